@@ -0,0 +1,556 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/s2gatev/gocompat/cst"
+)
+
+// Exit codes of the commands.
+const (
+	exitOK           = 0
+	exitIncompatible = 1
+	exitError        = 2
+)
+
+// scanOptions holds the command-line options controlling which files and
+// packages are part of the project interface.
+type scanOptions struct {
+	include   *string
+	exclude   *string
+	internal  *bool
+	typeCheck *bool
+}
+
+func newScanOptions(flags *flag.FlagSet) *scanOptions {
+	return &scanOptions{
+		include:   flags.String("include", "", "Comma-separated patterns of files to scan, e.g. \"api/...,*.go\". All files are scanned by default."),
+		exclude:   flags.String("exclude", "", "Comma-separated patterns of files and directories to skip, e.g. \"gen/...,*_gen.go\"."),
+		internal:  flags.Bool("internal", false, "Include internal and main packages in the interface."),
+		typeCheck: flags.Bool("types", false, "Type check the packages with go/types for exact types. Dependencies must be available from source."),
+	}
+}
+
+// compareOptions holds the command-line options controlling how changes
+// between two versions of the interface are classified.
+type compareOptions struct {
+	constValues    *string
+	fieldAdditions *string
+	funcValues     *string
+	consumers      *string
+	tagKeys        *string
+}
+
+func newCompareOptions(flags *flag.FlagSet) *compareOptions {
+	return &compareOptions{
+		constValues:    flags.String("const-values", "breaking", "Compatibility of changed constant values - \"breaking\" or \"compatible\"."),
+		fieldAdditions: flags.String("field-additions", "potentially-breaking", "Compatibility of fields added to structs with only exported fields - \"breaking\", \"potentially-breaking\" or \"compatible\"."),
+		funcValues:     flags.String("func-values", "potentially-breaking", "Compatibility of function changes keeping calls compiling but changing the function type, e.g. appending a variadic parameter - \"breaking\", \"potentially-breaking\" or \"compatible\"."),
+		consumers:      flags.String("consumers", "", "Comma-separated directories of consumer code scanned for unkeyed literals of structs with added fields."),
+		tagKeys:        flags.String("tag-keys", "", "Comma-separated struct tag keys compared in addition to "+strings.Join(cst.DefaultTagKeys, ", ")+"."),
+	}
+}
+
+// options returns the diff options selected on the command line.
+func (co *compareOptions) options() (cst.Options, error) {
+	options := cst.DefaultOptions()
+	if err := options.ConstValues.UnmarshalText([]byte(*co.constValues)); err != nil {
+		return options, fmt.Errorf("invalid -const-values: %v", err)
+	}
+	if err := options.FieldAdditions.UnmarshalText([]byte(*co.fieldAdditions)); err != nil {
+		return options, fmt.Errorf("invalid -field-additions: %v", err)
+	}
+	if err := options.FuncValues.UnmarshalText([]byte(*co.funcValues)); err != nil {
+		return options, fmt.Errorf("invalid -func-values: %v", err)
+	}
+	options.TagKeys = append(append([]string{}, cst.DefaultTagKeys...), splitPatterns(*co.tagKeys)...)
+	return options, nil
+}
+
+// report compares two versions of the project interface. Field additions
+// are confirmed against the consumer directories, if any.
+func (co *compareOptions) report(older, newer *cst.Project, options cst.Options) (*Report, error) {
+	changes := cst.DiffWith(older, newer, options)
+	if dirs := splitPatterns(*co.consumers); len(dirs) > 0 {
+		literals, err := findUnkeyedLiterals(dirs)
+		if err != nil {
+			return nil, err
+		}
+		confirmFieldAdditions(changes, literals)
+	}
+	return reportChanges(changes), nil
+}
+
+func (so *scanOptions) filter(root string) *FileFilter {
+	return &FileFilter{
+		Root:    root,
+		Include: splitPatterns(*so.include),
+		Exclude: splitPatterns(*so.exclude),
+	}
+}
+
+func (so *scanOptions) context(modulePath string) *InterfaceContext {
+	return &InterfaceContext{
+		Project:         &cst.Project{Packages: map[string]*cst.Package{}},
+		ModulePath:      modulePath,
+		IncludeInternal: *so.internal,
+		TypeCheck:       *so.typeCheck,
+	}
+}
+
+// scanDir builds the interface of the project in a directory.
+func (so *scanOptions) scanDir(dir string) (*cst.Project, error) {
+	context := so.context(readModulePath(dir))
+	if err := scanDir(dir, so.filter(dir), context); err != nil {
+		return nil, err
+	}
+	return context.Project, nil
+}
+
+// scanRevision builds the interface of the project in a directory at a git revision.
+func (so *scanOptions) scanRevision(dir, revision string) (*cst.Project, error) {
+	context := so.context(readModulePath(dir))
+	if err := scanRevision(dir, revision, so.filter(""), context); err != nil {
+		return nil, err
+	}
+	return context.Project, nil
+}
+
+// load builds a project interface from a source given on the command line,
+// which is either an index file, a project directory or a git revision.
+func (so *scanOptions) load(source string) (*cst.Project, error) {
+	if info, err := os.Stat(source); err == nil {
+		if info.IsDir() {
+			return so.scanDir(source)
+		}
+		return readIndex(source)
+	}
+	return so.scanRevision(".", source)
+}
+
+// readIndex reads a compatibility index file.
+func readIndex(path string) (*cst.Project, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return decodeIndex(content)
+}
+
+// writeIndex writes a compatibility index file in the given format.
+func writeIndex(path string, project *cst.Project, format string) error {
+	content, err := encodeIndex(project, format)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, content, 0644)
+}
+
+// command is a gocompat subcommand.
+type command struct {
+	Name        string
+	Args        string
+	Description string
+	run         func(flags *flag.FlagSet) int
+	flags       func(flags *flag.FlagSet)
+}
+
+// Run parses the command-line arguments of the command and executes it.
+func (c *command) Run(args []string) int {
+	flags := flag.NewFlagSet(c.Name, flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: gocompat %s [flags] %s\n\n%s\n\nFlags:\n", c.Name, c.Args, c.Description)
+		flags.PrintDefaults()
+	}
+	c.flags(flags)
+	if err := flags.Parse(args); err != nil {
+		return exitError
+	}
+	return c.run(flags)
+}
+
+var commands []*command
+
+func init() {
+	commands = []*command{
+		initCommand(),
+		checkCommand(),
+		updateCommand(),
+		diffCommand(),
+		showCommand(),
+		bumpCommand(),
+	}
+}
+
+// findCommand returns the subcommand with the given name, if any.
+func findCommand(name string) *command {
+	for _, cmd := range commands {
+		if cmd.Name == name {
+			return cmd
+		}
+	}
+	return nil
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: gocompat <command> [flags] [args]\n\nCommands:\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-8s %s\n", cmd.Name, strings.SplitN(cmd.Description, "\n", 2)[0])
+	}
+	fmt.Fprintf(os.Stderr, "\nWithout a command the project is checked against the index, which is then\nupdated if the interface is compatible. Flags:\n")
+	flag.PrintDefaults()
+}
+
+func initCommand() *command {
+	var options *scanOptions
+	var index, format *string
+	return &command{
+		Name:        "init",
+		Description: "Create the compatibility index of the current interface.",
+		flags: func(flags *flag.FlagSet) {
+			options = newScanOptions(flags)
+			index = flags.String("index", compatIndexFileName, "Path of the compatibility index.")
+			format = flags.String("format", jsonFormat, "Format of the compatibility index - \"json\" or the legacy \"gob\".")
+		},
+		run: func(flags *flag.FlagSet) int {
+			if _, err := os.Stat(*index); err == nil {
+				fmt.Printf("Compatibility index %s already exists, use update to replace it.\n", *index)
+				return exitError
+			}
+
+			current, err := options.scanDir(".")
+			if err != nil {
+				fmt.Println("Error when scanning project files.", err)
+				return exitError
+			}
+			if err := writeIndex(*index, current, *format); err != nil {
+				fmt.Println("Error when writing compatibility index.", err)
+				return exitError
+			}
+			return exitOK
+		},
+	}
+}
+
+func checkCommand() *command {
+	var options *scanOptions
+	var compare *compareOptions
+	var index, base *string
+	var asJSON *bool
+	return &command{
+		Name: "check",
+		Description: "Check that the current interface is compatible with the index or a git revision.\n" +
+			"Nothing is written, so it is safe to run in CI.",
+		flags: func(flags *flag.FlagSet) {
+			options = newScanOptions(flags)
+			index = flags.String("index", compatIndexFileName, "Path of the compatibility index.")
+			base = flags.String("base", "", "Compare against the project at a git revision, e.g. \"v1.4.0\", instead of the index.")
+			asJSON = flags.Bool("json", false, "Print all changes as a JSON document.")
+			compare = newCompareOptions(flags)
+		},
+		run: func(flags *flag.FlagSet) int {
+			diffOptions, err := compare.options()
+			if err != nil {
+				fmt.Println(err)
+				return exitError
+			}
+
+			var older *cst.Project
+			if *base != "" {
+				older, err = options.scanRevision(".", *base)
+			} else {
+				older, err = readIndex(*index)
+			}
+			if err != nil {
+				fmt.Println("Error when reading previous version.", err)
+				return exitError
+			}
+
+			current, err := options.scanDir(".")
+			if err != nil {
+				fmt.Println("Error when scanning project files.", err)
+				return exitError
+			}
+
+			report, err := compare.report(older, current, diffOptions)
+			if err != nil {
+				fmt.Println("Error when scanning consumers.", err)
+				return exitError
+			}
+			if *asJSON {
+				if err := report.WriteJSON(os.Stdout); err != nil {
+					fmt.Println("Error when writing report.", err)
+					return exitError
+				}
+			} else {
+				report.WriteText(os.Stdout, false)
+				if report.Compatible {
+					fmt.Println("OK")
+				} else {
+					fmt.Println("Not OK")
+				}
+			}
+
+			if !report.Compatible {
+				return exitIncompatible
+			}
+			return exitOK
+		},
+	}
+}
+
+func updateCommand() *command {
+	var options *scanOptions
+	var index, format *string
+	return &command{
+		Name:        "update",
+		Description: "Accept the current interface, replacing the compatibility index.",
+		flags: func(flags *flag.FlagSet) {
+			options = newScanOptions(flags)
+			index = flags.String("index", compatIndexFileName, "Path of the compatibility index.")
+			format = flags.String("format", jsonFormat, "Format of the compatibility index - \"json\" or the legacy \"gob\".")
+		},
+		run: func(flags *flag.FlagSet) int {
+			current, err := options.scanDir(".")
+			if err != nil {
+				fmt.Println("Error when scanning project files.", err)
+				return exitError
+			}
+			if err := writeIndex(*index, current, *format); err != nil {
+				fmt.Println("Error when writing compatibility index.", err)
+				return exitError
+			}
+			return exitOK
+		},
+	}
+}
+
+func diffCommand() *command {
+	var options *scanOptions
+	var compare *compareOptions
+	var asJSON *bool
+	return &command{
+		Name: "diff",
+		Args: "<old> [<new>]",
+		Description: "Print the changes between two versions of the interface.\n" +
+			"Each version is an index file, a project directory or a git revision.\n" +
+			"The new version defaults to the current directory.",
+		flags: func(flags *flag.FlagSet) {
+			options = newScanOptions(flags)
+			asJSON = flags.Bool("json", false, "Print the changes as a JSON document.")
+			compare = newCompareOptions(flags)
+		},
+		run: func(flags *flag.FlagSet) int {
+			if flags.NArg() < 1 || flags.NArg() > 2 {
+				flags.Usage()
+				return exitError
+			}
+			diffOptions, err := compare.options()
+			if err != nil {
+				fmt.Println(err)
+				return exitError
+			}
+
+			sources := []string{flags.Arg(0), "."}
+			if flags.NArg() == 2 {
+				sources[1] = flags.Arg(1)
+			}
+
+			projects := make([]*cst.Project, len(sources))
+			for i, source := range sources {
+				project, err := options.load(source)
+				if err != nil {
+					fmt.Printf("Error when loading %s. %v\n", source, err)
+					return exitError
+				}
+				projects[i] = project
+			}
+
+			report, err := compare.report(projects[0], projects[1], diffOptions)
+			if err != nil {
+				fmt.Println("Error when scanning consumers.", err)
+				return exitError
+			}
+			if *asJSON {
+				if err := report.WriteJSON(os.Stdout); err != nil {
+					fmt.Println("Error when writing report.", err)
+					return exitError
+				}
+			} else {
+				report.WriteText(os.Stdout, true)
+			}
+			return exitOK
+		},
+	}
+}
+
+func showCommand() *command {
+	var options *scanOptions
+	return &command{
+		Name: "show",
+		Args: "[<source>]",
+		Description: "Print the symbols of an interface, one per line.\n" +
+			"The source is an index file, a project directory or a git revision\n" +
+			"and defaults to the compatibility index.",
+		flags: func(flags *flag.FlagSet) {
+			options = newScanOptions(flags)
+		},
+		run: func(flags *flag.FlagSet) int {
+			source := compatIndexFileName
+			if flags.NArg() > 0 {
+				source = flags.Arg(0)
+			}
+
+			project, err := options.load(source)
+			if err != nil {
+				fmt.Printf("Error when loading %s. %v\n", source, err)
+				return exitError
+			}
+
+			for _, line := range cst.Symbols(project) {
+				fmt.Println(line)
+			}
+			return exitOK
+		},
+	}
+}
+
+// bumpResult is the JSON output of the bump command.
+type bumpResult struct {
+	Bump     Bump     `json:"bump"`
+	Latest   string   `json:"latest,omitempty"`
+	Next     string   `json:"next,omitempty"`
+	Problems []string `json:"problems,omitempty"`
+}
+
+func bumpCommand() *command {
+	var options *scanOptions
+	var compare *compareOptions
+	var index, base, version *string
+	var latest, verify, asJSON *bool
+	return &command{
+		Name: "bump",
+		Description: "Recommend a major, minor or patch version bump for the changes since the previous version.\n" +
+			"With -verify the changes since the latest vX.Y.Z tag are checked along with the module path,\n" +
+			"failing if a breaking change is released without a major version bump.",
+		flags: func(flags *flag.FlagSet) {
+			options = newScanOptions(flags)
+			index = flags.String("index", compatIndexFileName, "Path of the compatibility index.")
+			base = flags.String("base", "", "Compare against the project at a git revision instead of the index.")
+			latest = flags.Bool("latest", false, "Compare against the latest vX.Y.Z git tag instead of the index.")
+			verify = flags.Bool("verify", false, "Verify the release against the latest vX.Y.Z git tag and the module path.")
+			version = flags.String("version", "", "Version about to be released, e.g. \"v1.5.0\". Implies -verify.")
+			asJSON = flags.Bool("json", false, "Print the result as a JSON document.")
+			compare = newCompareOptions(flags)
+		},
+		run: func(flags *flag.FlagSet) int {
+			diffOptions, err := compare.options()
+			if err != nil {
+				fmt.Println(err)
+				return exitError
+			}
+
+			var release *Version
+			if *version != "" {
+				v, ok := parseVersion(*version)
+				if !ok {
+					fmt.Printf("Invalid version %s, expected vX.Y.Z.\n", *version)
+					return exitError
+				}
+				release = &v
+			}
+
+			result := bumpResult{}
+			verifying := *verify || release != nil
+			latestVersion, found := Version{}, false
+			if *latest || verifying {
+				latestVersion, found, err = latestVersionTag(".")
+				if err != nil {
+					fmt.Println("Error when reading version tags.", err)
+					return exitError
+				}
+				if found {
+					result.Latest = latestVersion.String()
+				}
+			}
+
+			// Releases are verified against the latest tag, as the bump is
+			// relative to it. Without a tag only the module path of the
+			// first release can be verified.
+			if verifying && *base != "" {
+				fmt.Println("The -base flag can not be combined with -verify or -version.")
+				return exitError
+			}
+			if verifying && !found && release == nil {
+				fmt.Println("No vX.Y.Z tag found, use -version to verify the first release.")
+				return exitError
+			}
+
+			var older *cst.Project
+			switch {
+			case *base != "":
+				older, err = options.scanRevision(".", *base)
+			case *latest || verifying && found:
+				if !found {
+					fmt.Println("No vX.Y.Z tag found.")
+					return exitError
+				}
+				older, err = options.scanRevision(".", latestVersion.String())
+			default:
+				older, err = readIndex(*index)
+			}
+			if err != nil {
+				fmt.Println("Error when reading previous version.", err)
+				return exitError
+			}
+
+			current, err := options.scanDir(".")
+			if err != nil {
+				fmt.Println("Error when scanning project files.", err)
+				return exitError
+			}
+
+			report, err := compare.report(older, current, diffOptions)
+			if err != nil {
+				fmt.Println("Error when scanning consumers.", err)
+				return exitError
+			}
+			result.Bump = report.Bump
+			if found {
+				result.Next = latestVersion.Next(result.Bump).String()
+				if verifying {
+					result.Problems = verifyRelease(latestVersion, result.Bump, release, readModulePath("."))
+				}
+			} else if verifying {
+				result.Problems = verifyModulePath(release.Major, readModulePath("."))
+			}
+
+			if *asJSON {
+				encoder := json.NewEncoder(os.Stdout)
+				encoder.SetIndent("", "\t")
+				if err := encoder.Encode(result); err != nil {
+					fmt.Println("Error when writing result.", err)
+					return exitError
+				}
+			} else {
+				fmt.Println(result.Bump)
+				if result.Next != "" {
+					fmt.Printf("Next version after %s: %s\n", result.Latest, result.Next)
+				}
+				for _, problem := range result.Problems {
+					fmt.Println("Error:", problem)
+				}
+			}
+
+			if len(result.Problems) > 0 {
+				return exitIncompatible
+			}
+			return exitOK
+		},
+	}
+}
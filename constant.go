@@ -0,0 +1,199 @@
+package main
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"strconv"
+	"strings"
+
+	"github.com/s2gatev/gocompat/cst"
+)
+
+// constValue is an evaluated constant with its type, which is nil for
+// untyped constants.
+type constValue struct {
+	Value constant.Value
+	Type  cst.Type
+}
+
+// untypedNames maps constant kinds to the names of their untyped types.
+var untypedNames = map[constant.Kind]string{
+	constant.Bool:    "untyped bool",
+	constant.String:  "untyped string",
+	constant.Int:     "untyped int",
+	constant.Float:   "untyped float",
+	constant.Complex: "untyped complex",
+}
+
+// constType returns the type of a constant, using the default type names
+// of untyped constants.
+func (cv *constValue) constType() cst.Type {
+	if cv.Type != nil {
+		return cv.Type
+	}
+	if name, ok := untypedNames[cv.Value.Kind()]; ok {
+		return &cst.SimpleType{name}
+	}
+	return nil
+}
+
+// constString renders a constant value exactly, preferring decimal notation
+// for floats which are exactly representable.
+func constString(value constant.Value) string {
+	if value.Kind() == constant.Float {
+		if f, exact := constant.Float64Val(value); exact {
+			return strconv.FormatFloat(f, 'g', -1, 64)
+		}
+	}
+	return value.ExactString()
+}
+
+// constKey returns the key of a constant in InterfaceContext.Constants.
+func constKey(packagePath, name string) string {
+	return packagePath + "." + name
+}
+
+// pendingConst is a constant whose value is evaluated once the constants
+// it refers to, possibly declared in files scanned later, are known.
+type pendingConst struct {
+	Key  string
+	Node *cst.Const
+	Expr ast.Expr
+	Iota int
+
+	// Type is the declared type of the constant, if any.
+	Type cst.Type
+
+	// File is a copy of the context of the file declaring the constant,
+	// which resolves the identifiers in its value.
+	File *InterfaceContext
+}
+
+// resolveConstants evaluates the pending constants until no more values can
+// be determined, so that the result does not depend on the order in which
+// files are scanned.
+func (ic *InterfaceContext) resolveConstants() {
+	for progress := true; progress; {
+		progress = false
+		var remaining []*pendingConst
+		for _, pending := range ic.pendingConsts {
+			evaluated := evalConst(pending.Expr, pending.Iota, pending.File)
+			if evaluated == nil {
+				remaining = append(remaining, pending)
+				continue
+			}
+
+			// The evaluated value may be shared with the constant it
+			// refers to, so it is copied before setting the type.
+			value := *evaluated
+			if pending.Type != nil {
+				value.Type = pending.Type
+			}
+			ic.Constants[pending.Key] = &value
+			pending.Node.Type = value.constType()
+			pending.Node.Value = constString(value.Value)
+			progress = true
+		}
+		ic.pendingConsts = remaining
+	}
+}
+
+// evalConst evaluates a constant expression. Identifiers refer to iota or to
+// the constants of the project known so far. The result is nil when the
+// expression can not be evaluated syntactically.
+func evalConst(expr ast.Expr, iota int, context *InterfaceContext) (result *constValue) {
+	// Invalid operations make go/constant panic, the value is unknown then.
+	defer func() {
+		if recover() != nil {
+			result = nil
+		}
+	}()
+
+	switch e := expr.(type) {
+	case *ast.BasicLit:
+		value := constant.MakeFromLiteral(e.Value, e.Kind, 0)
+		if value.Kind() == constant.Unknown {
+			return nil
+		}
+		return &constValue{Value: value}
+	case *ast.Ident:
+		switch e.Name {
+		case "iota":
+			return &constValue{Value: constant.MakeInt64(int64(iota))}
+		case "true", "false":
+			return &constValue{Value: constant.MakeBool(e.Name == "true")}
+		}
+		return context.Constants[constKey(context.CurrentPackage.Path, e.Name)]
+	case *ast.SelectorExpr:
+		if x, ok := e.X.(*ast.Ident); ok {
+			if path, ok := context.Imports[x.Name]; ok {
+				return context.Constants[constKey(path, e.Sel.Name)]
+			}
+		}
+	case *ast.ParenExpr:
+		return evalConst(e.X, iota, context)
+	case *ast.UnaryExpr:
+		x := evalConst(e.X, iota, context)
+		if x == nil {
+			return nil
+		}
+		return &constValue{Value: constant.UnaryOp(e.Op, x.Value, 0), Type: x.Type}
+	case *ast.BinaryExpr:
+		x, y := evalConst(e.X, iota, context), evalConst(e.Y, iota, context)
+		if x == nil || y == nil {
+			return nil
+		}
+		switch e.Op {
+		case token.SHL, token.SHR:
+			shift, ok := constant.Uint64Val(constant.ToInt(y.Value))
+			if !ok {
+				return nil
+			}
+			return &constValue{Value: constant.Shift(x.Value, e.Op, uint(shift)), Type: x.Type}
+		case token.EQL, token.NEQ, token.LSS, token.LEQ, token.GTR, token.GEQ:
+			return &constValue{Value: constant.MakeBool(constant.Compare(x.Value, e.Op, y.Value))}
+		}
+
+		op := e.Op
+		if op == token.QUO && x.Value.Kind() == constant.Int && y.Value.Kind() == constant.Int {
+			op = token.QUO_ASSIGN
+		}
+		valueType := x.Type
+		if valueType == nil {
+			valueType = y.Type
+		}
+		return &constValue{Value: constant.BinaryOp(x.Value, op, y.Value), Type: valueType}
+	case *ast.CallExpr:
+		if len(e.Args) != 1 {
+			return nil
+		}
+		x := evalConst(e.Args[0], iota, context)
+		if x == nil {
+			return nil
+		}
+		if ident, ok := e.Fun.(*ast.Ident); ok && ident.Name == "len" {
+			if x.Value.Kind() != constant.String {
+				return nil
+			}
+			length := constant.MakeInt64(int64(len(constant.StringVal(x.Value))))
+			return &constValue{Value: length, Type: &cst.SimpleType{"int"}}
+		}
+
+		// Otherwise the call is a conversion to a constant type.
+		value := x.Value
+		if name, ok := e.Fun.(*ast.Ident); ok {
+			switch {
+			case strings.HasPrefix(name.Name, "float"):
+				value = constant.ToFloat(value)
+			case strings.HasPrefix(name.Name, "int"), strings.HasPrefix(name.Name, "uint"):
+				value = constant.ToInt(value)
+			}
+		}
+		if value.Kind() == constant.Unknown {
+			return nil
+		}
+		return &constValue{Value: value, Type: extractType(e.Fun, context)}
+	}
+	return nil
+}
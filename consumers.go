@@ -0,0 +1,131 @@
+package main
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/s2gatev/gocompat/cst"
+)
+
+// findUnkeyedLiterals scans the Go files of consumer directories, including
+// tests, for unkeyed composite literals of types imported from other
+// packages. The types are returned as "path.Name".
+func findUnkeyedLiterals(dirs []string) (map[string]bool, error) {
+	literals := map[string]bool{}
+	for _, dir := range dirs {
+		err := filepath.Walk(dir, func(path string, f os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if f.IsDir() {
+				if path != dir && (strings.HasPrefix(f.Name(), ".") || f.Name() == "vendor") {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !strings.HasSuffix(path, ".go") {
+				return nil
+			}
+
+			file, err := parser.ParseFile(token.NewFileSet(), path, nil, 0)
+			if err != nil {
+				return nil
+			}
+			collectUnkeyedLiterals(file, literals)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return literals, nil
+}
+
+// collectUnkeyedLiterals records the imported types of the unkeyed composite
+// literals in a file, including literals whose type is elided within slice,
+// array and map literals.
+func collectUnkeyedLiterals(file *ast.File, literals map[string]bool) {
+	imports := map[string]string{}
+	for _, spec := range file.Imports {
+		path, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+		if spec.Name == nil {
+			imports[importName(path)] = path
+		} else {
+			imports[spec.Name.Name] = path
+		}
+	}
+
+	var visit func(lit *ast.CompositeLit, litType ast.Expr)
+	visit = func(lit *ast.CompositeLit, litType ast.Expr) {
+		if lit.Type != nil {
+			litType = lit.Type
+		}
+
+		var elemType ast.Expr
+		switch t := litType.(type) {
+		case *ast.ArrayType:
+			elemType = t.Elt
+		case *ast.MapType:
+			elemType = t.Value
+		case *ast.StarExpr:
+			litType = t.X
+		}
+
+		if sel, ok := litType.(*ast.SelectorExpr); ok && len(lit.Elts) > 0 {
+			if _, keyed := lit.Elts[0].(*ast.KeyValueExpr); !keyed {
+				if x, ok := sel.X.(*ast.Ident); ok && imports[x.Name] != "" {
+					literals[imports[x.Name]+"."+sel.Sel.Name] = true
+				}
+			}
+		}
+
+		for _, elt := range lit.Elts {
+			if kv, ok := elt.(*ast.KeyValueExpr); ok {
+				elt = kv.Value
+			}
+			if unary, ok := elt.(*ast.UnaryExpr); ok && unary.Op == token.AND {
+				elt = unary.X
+			}
+			if inner, ok := elt.(*ast.CompositeLit); ok {
+				visit(inner, elemType)
+			}
+		}
+	}
+
+	ast.Inspect(file, func(node ast.Node) bool {
+		if lit, ok := node.(*ast.CompositeLit); ok {
+			visit(lit, nil)
+			return false
+		}
+		return true
+	})
+}
+
+// confirmFieldAdditions reclassifies the potentially breaking field
+// additions, which are breaking when the consumers use unkeyed literals of
+// the struct and compatible otherwise.
+func confirmFieldAdditions(changes []cst.Change, literals map[string]bool) {
+	for i, change := range changes {
+		if change.Compatibility != cst.PotentiallyBreaking || change.Kind != cst.Added || change.Symbol != "field" {
+			continue
+		}
+
+		typeName := strings.TrimPrefix(change.Path, change.Package+".")
+		if dot := strings.Index(typeName, "."); dot >= 0 {
+			typeName = typeName[:dot]
+		}
+		if literals[change.Package+"."+typeName] {
+			changes[i].Compatibility = cst.Breaking
+		} else {
+			changes[i].Compatibility = cst.Compatible
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"go/parser"
+	"go/token"
+	"testing"
+
+	"github.com/s2gatev/gocompat/cst"
+)
+
+func TestCollectUnkeyedLiterals(t *testing.T) {
+	source := `
+package consumer
+
+import (
+	"example.com/geo"
+	shapes "example.com/shapes"
+)
+
+var a = geo.Point{1, 2}
+var b = []*geo.Line{{geo.Point{}, geo.Point{}}}
+var c = map[string]shapes.Circle{"unit": {Radius: 1}}
+var d = &shapes.Square{Side: 1}
+`
+
+	file, err := parser.ParseFile(token.NewFileSet(), "consumer.go", source, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	literals := map[string]bool{}
+	collectUnkeyedLiterals(file, literals)
+
+	expected := map[string]bool{
+		"example.com/geo.Point":     true,
+		"example.com/geo.Line":      true,
+		"example.com/shapes.Circle": false,
+		"example.com/shapes.Square": false,
+	}
+	for name, unkeyed := range expected {
+		if literals[name] != unkeyed {
+			t.Errorf("Expected unkeyed literal of %s to be found: %v.", name, unkeyed)
+		}
+	}
+}
+
+func TestConfirmFieldAdditions(t *testing.T) {
+	changes := []cst.Change{
+		{Path: "p/geo.Point.Z", Package: "p/geo", Symbol: "field", Kind: cst.Added, Compatibility: cst.PotentiallyBreaking},
+		{Path: "p/geo.Line.Width", Package: "p/geo", Symbol: "field", Kind: cst.Added, Compatibility: cst.PotentiallyBreaking},
+	}
+	confirmFieldAdditions(changes, map[string]bool{"p/geo.Point": true})
+
+	if changes[0].Compatibility != cst.Breaking {
+		t.Errorf("Expected the field added to Point to be breaking, got %v.", changes[0].Compatibility)
+	}
+	if changes[1].Compatibility != cst.Compatible {
+		t.Errorf("Expected the field added to Line to be compatible, got %v.", changes[1].Compatibility)
+	}
+}
@@ -0,0 +1,145 @@
+package cst
+
+import "strings"
+
+// Alias represents a type alias node - type A = B.
+type Alias struct {
+	Name       string
+	TypeParams *TypeParams
+	Type       Type
+	Pos        string
+}
+
+func (older *Alias) Compare(n Node) bool {
+	return equivalent(older, n)
+}
+
+// Diff compares the targets of aliases. Aliases denote their target, so
+// replacing the target with an identical type, e.g. another alias of it, is
+// compatible, while turning an alias into a defined type changes its
+// identity and method set.
+func (older *Alias) Diff(ctx *DiffContext, at Location, n Node) {
+	if newer, ok := n.(*Alias); ok {
+		if older.Name != newer.Name {
+			ctx.report(at, Changed, older, newer)
+			return
+		}
+
+		names := typeParamRenaming(older.TypeParams, newer.TypeParams)
+		diffTypeParams(ctx, at, older.TypeParams, newer.TypeParams.renamed(names))
+		diffType(ctx, at, older.Type, renameType(newer.Type, names))
+	} else {
+		ctx.report(at, Changed, older, n)
+	}
+}
+
+func (a *Alias) String() string {
+	return "type " + a.Name + a.TypeParams.String() + " = " + a.Type.String()
+}
+
+// sameType returns if two types are equal or identical through aliases.
+func (ctx *DiffContext) sameType(pkg string, older, newer Type) bool {
+	return older.Compare(newer) || ctx.identical(pkg, older, newer)
+}
+
+// identical returns if two types are identical once the aliases declared in
+// the compared projects are replaced by their targets.
+func (ctx *DiffContext) identical(pkg string, older, newer Type) bool {
+	if ctx.older == nil || ctx.newer == nil {
+		return false
+	}
+	return ctx.older.unalias(pkg, older).Compare(ctx.newer.unalias(pkg, newer))
+}
+
+// unalias returns a type with the aliases declared in the project replaced
+// by their targets. Named types of the project are qualified with their
+// package, so that types named differently in different packages compare
+// equal. Struct and interface literals are not descended into.
+func (p *Project) unalias(pkg string, t Type) Type {
+	return p.resolve(pkg, t, map[*Alias]bool{})
+}
+
+func (p *Project) resolve(pkg string, t Type, visited map[*Alias]bool) Type {
+	switch t := t.(type) {
+	case *SimpleType:
+		// Named types may be prefixed, e.g. *T or ...T.
+		name := strings.TrimLeft(t.Name, "*.")
+		resolved := p.resolveName(pkg, name, visited)
+		if resolved == nil {
+			return t
+		}
+		prefix := t.Name[:len(t.Name)-len(name)]
+		for strings.HasSuffix(prefix, "*") || strings.HasSuffix(prefix, "...") {
+			if strings.HasSuffix(prefix, "*") {
+				resolved = &PointerType{Elem: resolved}
+				prefix = strings.TrimSuffix(prefix, "*")
+			} else {
+				resolved = &VariadicType{Elem: resolved}
+				prefix = strings.TrimSuffix(prefix, "...")
+			}
+		}
+		return resolved
+	case *QualifiedType:
+		if resolved := p.resolveName(t.Path, t.Name, visited); resolved != nil {
+			return resolved
+		}
+		return t
+	case *PointerType:
+		return &PointerType{Elem: p.resolve(pkg, t.Elem, visited)}
+	case *VariadicType:
+		return &VariadicType{Elem: p.resolve(pkg, t.Elem, visited)}
+	case *SliceType:
+		return &SliceType{Elem: p.resolve(pkg, t.Elem, visited)}
+	case *ArrayType:
+		return &ArrayType{Len: t.Len, Elem: p.resolve(pkg, t.Elem, visited)}
+	case *MapType:
+		return &MapType{Key: p.resolve(pkg, t.Key, visited), Value: p.resolve(pkg, t.Value, visited)}
+	case *ChanType:
+		return &ChanType{Dir: t.Dir, Elem: p.resolve(pkg, t.Elem, visited)}
+	case *Instance:
+		instance := &Instance{Type: p.resolve(pkg, t.Type, visited)}
+		for _, arg := range t.Args {
+			instance.Args = append(instance.Args, p.resolve(pkg, arg, visited))
+		}
+		return instance
+	case *FuncType:
+		funcType := &FuncType{}
+		if t.Params != nil {
+			funcType.Params = &Params{p.resolveAll(pkg, t.Params.Types, visited)}
+		}
+		if t.Results != nil {
+			funcType.Results = &Results{p.resolveAll(pkg, t.Results.Types, visited)}
+		}
+		return funcType
+	default:
+		return t
+	}
+}
+
+func (p *Project) resolveAll(pkg string, types []Type, visited map[*Alias]bool) []Type {
+	resolved := make([]Type, len(types))
+	for i, t := range types {
+		resolved[i] = p.resolve(pkg, t, visited)
+	}
+	return resolved
+}
+
+// resolveName resolves a type name declared in a package of the project.
+// It returns nil for names which are not declared in the project.
+func (p *Project) resolveName(pkg, name string, visited map[*Alias]bool) Type {
+	if p.Packages[pkg] == nil {
+		return nil
+	}
+	switch n := p.Packages[pkg].declaration(name).(type) {
+	case *Alias:
+		if visited[n] || n.TypeParams != nil {
+			return nil
+		}
+		visited[n] = true
+		return p.resolve(pkg, n.Type, visited)
+	case *TypeDef:
+		return &QualifiedType{Path: pkg, Name: name}
+	default:
+		return nil
+	}
+}
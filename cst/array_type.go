@@ -0,0 +1,19 @@
+package cst
+
+// ArrayType represents an array type node - [4]int, [N]string, etc...
+type ArrayType struct {
+	Len  string
+	Elem Type
+}
+
+func (older *ArrayType) Compare(n Node) bool {
+	if newer, ok := n.(*ArrayType); ok {
+		return older.Len == newer.Len && older.Elem.Compare(newer.Elem)
+	} else {
+		return false
+	}
+}
+
+func (t *ArrayType) String() string {
+	return "[" + t.Len + "]" + t.Elem.String()
+}
@@ -0,0 +1,40 @@
+package cst
+
+// ChanDir is the direction of a channel type.
+type ChanDir int
+
+const (
+	// BothDir marks a bidirectional channel.
+	BothDir ChanDir = iota
+
+	// SendDir marks a send-only channel.
+	SendDir
+
+	// RecvDir marks a receive-only channel.
+	RecvDir
+)
+
+// ChanType represents a channel type node - chan int, chan<- T, <-chan T, etc...
+type ChanType struct {
+	Dir  ChanDir
+	Elem Type
+}
+
+func (older *ChanType) Compare(n Node) bool {
+	if newer, ok := n.(*ChanType); ok {
+		return older.Dir == newer.Dir && older.Elem.Compare(newer.Elem)
+	} else {
+		return false
+	}
+}
+
+func (t *ChanType) String() string {
+	switch t.Dir {
+	case SendDir:
+		return "chan<- " + t.Elem.String()
+	case RecvDir:
+		return "<-chan " + t.Elem.String()
+	default:
+		return "chan " + t.Elem.String()
+	}
+}
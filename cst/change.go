@@ -0,0 +1,323 @@
+package cst
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ChangeKind classifies what happened to a symbol between two versions.
+type ChangeKind int
+
+const (
+	// Removed marks a symbol missing from the newer version.
+	Removed ChangeKind = iota
+
+	// Changed marks a symbol present in both versions with different definitions.
+	Changed
+
+	// Added marks a symbol missing from the older version.
+	Added
+
+	// ValueChanged marks a constant whose value changed.
+	ValueChanged
+)
+
+func (k ChangeKind) String() string {
+	switch k {
+	case Removed:
+		return "removed"
+	case Changed:
+		return "changed"
+	case Added:
+		return "added"
+	case ValueChanged:
+		return "value changed"
+	default:
+		return "unknown"
+	}
+}
+
+func (k ChangeKind) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+// Compatibility classifies the effect of a change on the users of a symbol.
+type Compatibility int
+
+const (
+	// Breaking marks changes which may stop users' code from compiling.
+	Breaking Compatibility = iota
+
+	// Compatible marks changes which do not affect existing users.
+	Compatible
+
+	// PotentiallyBreaking marks changes which break only some uses of a
+	// symbol, e.g. unkeyed composite literals of a struct with a new field.
+	PotentiallyBreaking
+)
+
+func (c Compatibility) String() string {
+	switch c {
+	case Breaking:
+		return "breaking"
+	case Compatible:
+		return "compatible"
+	case PotentiallyBreaking:
+		return "potentially breaking"
+	default:
+		return "unknown"
+	}
+}
+
+func (c Compatibility) MarshalText() ([]byte, error) {
+	return []byte(c.String()), nil
+}
+
+func (c *Compatibility) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "breaking":
+		*c = Breaking
+	case "compatible":
+		*c = Compatible
+	case "potentially breaking", "potentially-breaking":
+		*c = PotentiallyBreaking
+	default:
+		return fmt.Errorf("unknown compatibility %q", text)
+	}
+	return nil
+}
+
+// Options configures how changes are classified.
+type Options struct {
+	// ConstValues is the compatibility of changed constant values.
+	ConstValues Compatibility
+
+	// FieldAdditions is the compatibility of fields added to structs whose
+	// fields are all exported, which breaks their unkeyed composite literals.
+	FieldAdditions Compatibility
+
+	// FuncValues is the compatibility of changes to functions and methods
+	// which keep their calls compiling but change the type of their values,
+	// e.g. appending a variadic parameter. It breaks assigning the function
+	// to variables of its former type and implementing interfaces.
+	FuncValues Compatibility
+
+	// TagKeys lists the struct tag keys whose values are compared.
+	TagKeys []string
+}
+
+// DefaultOptions returns the options classifying changes by the default rules.
+func DefaultOptions() Options {
+	return Options{
+		ConstValues:    Breaking,
+		FieldAdditions: PotentiallyBreaking,
+		FuncValues:     PotentiallyBreaking,
+		TagKeys:        DefaultTagKeys,
+	}
+}
+
+// Location identifies a node within the project being compared.
+type Location struct {
+	// Path is the dot-separated path to the symbol, e.g. "p.MyStruct.Field".
+	Path string
+
+	// Pos is the file:line position of the closest enclosing declaration.
+	Pos string
+
+	// Package is the import path of the package containing the symbol.
+	Package string
+
+	// Symbol is the kind of the closest enclosing declaration, e.g. "func".
+	Symbol string
+}
+
+// Child returns the location of a nested symbol. The position and symbol
+// kind are inherited from the parent unless the symbol has its own.
+func (l Location) Child(name, pos string) Location {
+	child := l
+	child.Path = name
+	if l.Path != "" {
+		child.Path = l.Path + "." + name
+	}
+	if pos != "" {
+		child.Pos = pos
+	}
+	return child
+}
+
+// Of returns the location with the given symbol kind.
+func (l Location) Of(symbol string) Location {
+	l.Symbol = symbol
+	return l
+}
+
+// Change describes a single difference between two versions of a symbol.
+type Change struct {
+	Path          string        `json:"path"`
+	Package       string        `json:"package"`
+	Symbol        string        `json:"symbol"`
+	Kind          ChangeKind    `json:"kind"`
+	Compatibility Compatibility `json:"compatibility"`
+	Old           string        `json:"old,omitempty"`
+	New           string        `json:"new,omitempty"`
+	Pos           string        `json:"position,omitempty"`
+
+	// Note explains the compatibility of a change, e.g. the interface a
+	// relaxed parameter type is implemented by.
+	Note string `json:"note,omitempty"`
+}
+
+// IsBreaking returns if the change may break users of the symbol.
+func (c Change) IsBreaking() bool {
+	return c.Compatibility == Breaking
+}
+
+func (c Change) String() string {
+	var description string
+	switch c.Kind {
+	case Removed:
+		description = fmt.Sprintf("%s: removed %s", c.Path, c.Old)
+	case Added:
+		description = fmt.Sprintf("%s: added %s", c.Path, c.New)
+	default:
+		description = fmt.Sprintf("%s: %s from %s to %s", c.Path, c.Kind, c.Old, c.New)
+	}
+
+	if c.Compatibility != Breaking || c.Note != "" {
+		qualifier := c.Compatibility.String()
+		if c.Note != "" {
+			qualifier += ", " + c.Note
+		}
+		description += " (" + qualifier + ")"
+	}
+	if c.Pos != "" {
+		return c.Pos + ": " + description
+	}
+	return description
+}
+
+// DiffContext collects the changes found while walking two versions
+// of the concrete syntax tree.
+type DiffContext struct {
+	Changes []Change
+
+	// The compared projects, used to resolve types referred to by name.
+	older, newer *Project
+
+	options Options
+}
+
+// report records a breaking change.
+func (ctx *DiffContext) report(at Location, kind ChangeKind, older, newer Node) {
+	ctx.reportAs(at, kind, Breaking, older, newer)
+}
+
+// reportAs records a change with the given compatibility.
+func (ctx *DiffContext) reportAs(at Location, kind ChangeKind, compatibility Compatibility, older, newer Node) {
+	ctx.explain(at, kind, compatibility, older, newer, "")
+}
+
+// explain records a change with the given compatibility along with a note
+// explaining it.
+func (ctx *DiffContext) explain(at Location, kind ChangeKind, compatibility Compatibility, older, newer Node, note string) {
+	change := Change{
+		Path:          at.Path,
+		Package:       at.Package,
+		Symbol:        at.Symbol,
+		Kind:          kind,
+		Compatibility: compatibility,
+		Pos:           at.Pos,
+		Note:          note,
+	}
+	if older != nil {
+		change.Old = older.String()
+	}
+	if newer != nil {
+		change.New = newer.String()
+	}
+	ctx.Changes = append(ctx.Changes, change)
+}
+
+// BreakingChanges filters the breaking changes from a list of changes.
+func BreakingChanges(changes []Change) []Change {
+	breaking := []Change{}
+	for _, change := range changes {
+		if change.IsBreaking() {
+			breaking = append(breaking, change)
+		}
+	}
+	return breaking
+}
+
+// Diff returns all changes between two versions of a project, both
+// breaking and compatible ones.
+func Diff(older, newer *Project) []Change {
+	return DiffWith(older, newer, DefaultOptions())
+}
+
+// DiffWith returns all changes between two versions of a project, classified
+// according to the given options.
+func DiffWith(older, newer *Project, options Options) []Change {
+	ctx := &DiffContext{options: options}
+	older.Diff(ctx, Location{}, newer)
+	return ctx.Changes
+}
+
+// equivalent reports whether newer has no changes compared to older.
+func equivalent(older Differ, newer Node) bool {
+	ctx := &DiffContext{options: DefaultOptions()}
+	older.Diff(ctx, Location{}, newer)
+	return len(BreakingChanges(ctx.Changes)) == 0
+}
+
+// diffType compares two types, descending into composite types when possible.
+func diffType(ctx *DiffContext, at Location, older, newer Type) {
+	if d, ok := older.(Differ); ok {
+		if _, ok := newer.(Differ); !ok && ctx.identical(at.Package, older, newer) {
+			return
+		}
+		d.Diff(ctx, at, newer)
+	} else if !older.Compare(newer) && !ctx.identical(at.Package, older, newer) {
+		ctx.report(at, Changed, older, newer)
+	}
+}
+
+// joinTypes renders a list of types separated by commas.
+func joinTypes(types []Type) string {
+	names := make([]string, len(types))
+	for i, t := range types {
+		names[i] = t.String()
+	}
+	return strings.Join(names, ", ")
+}
+
+// sortedKeys returns the keys of a node map in lexical order so that changes
+// are reported deterministically.
+func sortedKeys(m interface{}) []string {
+	var keys []string
+	switch m := m.(type) {
+	case map[string]Node:
+		for k := range m {
+			keys = append(keys, k)
+		}
+	case map[string]*Package:
+		for k := range m {
+			keys = append(keys, k)
+		}
+	case map[string]*Field:
+		for k := range m {
+			keys = append(keys, k)
+		}
+	case map[string]*Func:
+		for k := range m {
+			keys = append(keys, k)
+		}
+	case map[string]member:
+		for k := range m {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
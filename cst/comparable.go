@@ -0,0 +1,83 @@
+package cst
+
+import "strings"
+
+// comparability describes if values of a type can be compared with == and
+// used as map keys.
+type comparability bool
+
+func (c comparability) Compare(n Node) bool {
+	return c == n
+}
+
+func (c comparability) String() string {
+	if c {
+		return "comparable"
+	}
+	return "not comparable"
+}
+
+// comparable returns if values of a type are comparable. Named types are
+// resolved within the project, types which can not be resolved, e.g. types
+// of other modules or type parameters, are assumed to be comparable.
+func (p *Project) comparable(pkg string, t Type) bool {
+	return p.isComparable(pkg, t, map[string]bool{})
+}
+
+func (p *Project) isComparable(pkg string, t Type, visited map[string]bool) bool {
+	switch t := t.(type) {
+	case *SliceType, *MapType, *FuncType:
+		return false
+	case *ArrayType:
+		return p.isComparable(pkg, t.Elem, visited)
+	case *Struct:
+		for _, name := range sortedKeys(t.Fields) {
+			if !p.isComparable(pkg, t.Fields[name].Type, visited) {
+				return false
+			}
+		}
+		return true
+	case *Instance:
+		return p.isComparable(pkg, t.Type, visited)
+	case *SimpleType:
+		if strings.HasPrefix(t.Name, "*") {
+			return true
+		}
+		return p.isComparableName(pkg, t.Name, visited)
+	case *QualifiedType:
+		return p.isComparableName(t.Path, t.Name, visited)
+	default:
+		return true
+	}
+}
+
+func (p *Project) isComparableName(pkg, name string, visited map[string]bool) bool {
+	key := pkg + "." + name
+	if p == nil || p.Packages[pkg] == nil || visited[key] {
+		return true
+	}
+	visited[key] = true
+
+	switch n := p.Packages[pkg].declaration(name).(type) {
+	case *TypeDef:
+		if n.Type != nil {
+			return p.isComparable(pkg, n.Type, visited)
+		}
+	case *Alias:
+		return p.isComparable(pkg, n.Type, visited)
+	}
+	return true
+}
+
+// diffComparability reports types which are no longer comparable, which
+// breaks their use with == and as map keys.
+func (older *TypeDef) diffComparability(ctx *DiffContext, at Location, newer *TypeDef) {
+	if older.Type == nil || newer.Type == nil {
+		return
+	}
+	oComparable := comparability(ctx.older.comparable(at.Package, older.Type))
+	nComparable := comparability(ctx.newer.comparable(at.Package, newer.Type))
+	if oComparable && !nComparable {
+		ctx.report(at, Changed, oComparable, nComparable)
+	}
+}
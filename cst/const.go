@@ -0,0 +1,60 @@
+package cst
+
+// Const represents a constant definition node.
+type Const struct {
+	Name string
+
+	// Type is the declared type of the constant, or its default type such as
+	// "untyped int" for untyped constants. It is nil when it can not be
+	// determined syntactically.
+	Type Type
+
+	// Value is the exact value of the constant, or empty when it can not be
+	// evaluated syntactically.
+	Value string
+
+	Pos string
+}
+
+func (older *Const) Compare(n Node) bool {
+	return equivalent(older, n)
+}
+
+// Diff reports changed types as breaking. Changed values are reported with
+// the compatibility configured for constant values, since they only affect
+// users relying on the value itself, e.g. in wire formats.
+func (older *Const) Diff(ctx *DiffContext, at Location, n Node) {
+	if newer, ok := n.(*Const); ok {
+		if older.Name != newer.Name {
+			ctx.report(at, Changed, older, newer)
+			return
+		}
+
+		if older.Type == nil || newer.Type == nil {
+			if older.Type != newer.Type {
+				ctx.report(at, Changed, older, newer)
+				return
+			}
+		} else if !older.Type.Compare(newer.Type) {
+			ctx.report(at, Changed, older, newer)
+			return
+		}
+
+		if older.Value != newer.Value {
+			ctx.reportAs(at, ValueChanged, ctx.options.ConstValues, older, newer)
+		}
+	} else {
+		ctx.report(at, Changed, older, n)
+	}
+}
+
+func (c *Const) String() string {
+	s := "const " + c.Name
+	if c.Type != nil {
+		s += " " + c.Type.String()
+	}
+	if c.Value != "" {
+		s += " = " + c.Value
+	}
+	return s
+}
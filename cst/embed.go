@@ -0,0 +1,203 @@
+package cst
+
+import (
+	"go/token"
+	"strings"
+)
+
+// member is a field or method promoted through embedded fields.
+type member struct {
+	Node Node
+
+	// Origin is the qualified name of the type declaring the member.
+	Origin string
+
+	// Indirect is set when the member is reached through an embedded
+	// pointer, so that methods with pointer recievers are promoted to values.
+	Indirect bool
+}
+
+// pos returns the position of members declared by the type itself. Promoted
+// members are reported at the position of the type.
+func (m member) pos() string {
+	if f, ok := m.Node.(*Func); ok && m.Origin == "" {
+		return f.Pos
+	}
+	return ""
+}
+
+// embedding is a struct reached through embedded fields.
+type embedding struct {
+	pkg      string
+	typ      *Struct
+	indirect bool
+}
+
+// lookup resolves the type definition a type refers to, along with the
+// path of the package declaring it. Only types recorded in the project can
+// be resolved.
+func (p *Project) lookup(pkg string, t Type) (*TypeDef, string) {
+	var name string
+	switch t := t.(type) {
+	case *PointerType:
+		return p.lookup(pkg, t.Elem)
+	case *Instance:
+		return p.lookup(pkg, t.Type)
+	case *SimpleType:
+		name = strings.TrimPrefix(t.Name, "*")
+	case *QualifiedType:
+		pkg, name = t.Path, t.Name
+	default:
+		return nil, ""
+	}
+
+	if p == nil || p.Packages[pkg] == nil {
+		return nil, ""
+	}
+	if def, ok := p.Packages[pkg].declaration(name).(*TypeDef); ok {
+		return def, pkg
+	}
+	return nil, ""
+}
+
+// promoted returns the exported members promoted to a type definition
+// through its embedded fields, following the selector rules of the
+// language: shallower members shadow deeper ones and members found more than
+// once at the same depth are ambiguous and not promoted at all.
+func (p *Project) promoted(pkg string, def *TypeDef) map[string]member {
+	members := map[string]member{}
+	blocked := map[string]bool{}
+	for name := range def.Methods {
+		blocked[name] = true
+	}
+
+	s, ok := def.Type.(*Struct)
+	if !ok {
+		return members
+	}
+	for name := range s.Fields {
+		blocked[name] = true
+	}
+
+	visited := map[*TypeDef]bool{def: true}
+	level := []embedding{{pkg, s, false}}
+	for len(level) > 0 {
+		found := map[string][]member{}
+		var next []embedding
+		for _, e := range level {
+			for _, name := range sortedKeys(e.typ.Fields) {
+				field := e.typ.Fields[name]
+				if !field.Embedded {
+					continue
+				}
+				embedded, embeddedPkg := p.lookup(e.pkg, field.Type)
+				if embedded == nil || visited[embedded] {
+					continue
+				}
+				visited[embedded] = true
+
+				origin := embeddedPkg + "." + embedded.Name
+				indirect := e.indirect || isPointer(field.Type)
+				for name, method := range embedded.Methods {
+					found[name] = append(found[name], member{method, origin, indirect})
+				}
+				switch t := embedded.Type.(type) {
+				case *Struct:
+					for name, field := range t.Fields {
+						found[name] = append(found[name], member{field, origin, indirect})
+					}
+					next = append(next, embedding{embeddedPkg, t, indirect})
+				case *Interface:
+					for name, method := range p.methodSet(embeddedPkg, t).methods {
+						found[name] = append(found[name], member{method.Node, origin, indirect})
+					}
+				}
+			}
+		}
+
+		for name, candidates := range found {
+			if blocked[name] {
+				continue
+			}
+			blocked[name] = true
+			if len(candidates) == 1 && token.IsExported(name) {
+				members[name] = candidates[0]
+			}
+		}
+		level = next
+	}
+	return members
+}
+
+// exportedOrigin returns if a member originates from an exported type,
+// whose changes are reported where it is declared.
+func exportedOrigin(origin string) bool {
+	return token.IsExported(origin[strings.LastIndex(origin, ".")+1:])
+}
+
+// declares returns if a type definition declares a field or method itself.
+func (t *TypeDef) declares(name string) bool {
+	if _, ok := t.Methods[name]; ok {
+		return true
+	}
+	if s, ok := t.Type.(*Struct); ok {
+		_, ok := s.Fields[name]
+		return ok
+	}
+	return false
+}
+
+// diffPromoted reports promoted members which are no longer reachable and
+// members which are now promoted from a different type.
+func (older *TypeDef) diffPromoted(ctx *DiffContext, at Location, newer *TypeDef) {
+	oMembers := ctx.older.promoted(at.Package, older)
+	nMembers := ctx.newer.promoted(at.Package, newer)
+
+	for _, name := range sortedKeys(oMembers) {
+		oMember := oMembers[name]
+		kind := "method"
+		if _, ok := oMember.Node.(*Field); ok {
+			kind = "field"
+		}
+		child := at.Child(name, "").Of(kind)
+
+		nMember, ok := nMembers[name]
+		if !ok {
+			if !newer.declares(name) {
+				ctx.report(child, Removed, oMember.Node, nil)
+			}
+			continue
+		}
+
+		// Changes of members promoted from the same exported type are
+		// reported where the type is declared.
+		if oMember.Origin == nMember.Origin && exportedOrigin(oMember.Origin) {
+			continue
+		}
+		switch o := oMember.Node.(type) {
+		case *Func:
+			if n, ok := nMember.Node.(*Func); ok {
+				o.diffSignature(ctx, child, n, ctx.options.FuncValues)
+			} else {
+				ctx.report(child, Changed, o, nMember.Node)
+			}
+		case *Field:
+			if n, ok := nMember.Node.(*Field); ok {
+				diffType(ctx, child, o.Type, n.Type)
+			} else {
+				ctx.report(child, Changed, o, nMember.Node)
+			}
+		}
+	}
+}
+
+// isPointer returns if a type is a pointer type.
+func isPointer(t Type) bool {
+	switch t := t.(type) {
+	case *PointerType:
+		return true
+	case *SimpleType:
+		return strings.HasPrefix(t.Name, "*")
+	}
+	return false
+}
@@ -4,20 +4,42 @@ package cst
 type Field struct {
 	Name string
 	Type Type
+	Pos  string
+
+	// Embedded marks fields declared without a name, whose fields and
+	// methods are promoted to the struct. Their name is the name of the type.
+	Embedded bool
+
+	// Tag is the unquoted struct tag of the field.
+	Tag string
 }
 
 func (older *Field) Compare(n Node) bool {
+	return equivalent(older, n)
+}
+
+func (older *Field) Diff(ctx *DiffContext, at Location, n Node) {
 	if newer, ok := n.(*Field); ok {
 		if older.Name != newer.Name {
-			return false
+			ctx.report(at, Changed, older, newer)
+			return
 		}
 
-		if !older.Type.Compare(newer.Type) {
-			return false
+		// Turning an embedded field into a named one stops the promotion.
+		if older.Embedded && !newer.Embedded {
+			ctx.report(at, Changed, older, newer)
 		}
 
-		return true
+		diffType(ctx, at, older.Type, newer.Type)
+		older.diffTags(ctx, at, newer)
 	} else {
-		return false
+		ctx.report(at, Changed, older, n)
+	}
+}
+
+func (f *Field) String() string {
+	if f.Embedded {
+		return f.Type.String() + tagString(f.Tag)
 	}
+	return f.Name + " " + f.Type.String() + tagString(f.Tag)
 }
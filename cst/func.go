@@ -1,45 +1,92 @@
 package cst
 
+import "strings"
+
 // Func represents a function definition node.
 type Func struct {
-	Name      string
-	Recievers *Recievers
-	Params    *Params
-	Results   *Results
+	Name       string
+	TypeParams *TypeParams
+	Recievers  *Recievers
+	Params     *Params
+	Results    *Results
+	Pos        string
 }
 
 func (older *Func) Compare(n Node) bool {
+	return equivalent(older, n)
+}
+
+func (older *Func) Diff(ctx *DiffContext, at Location, n Node) {
 	if newer, ok := n.(*Func); ok {
+		newer = newer.renamed(typeParamRenaming(older.TypeParams, newer.TypeParams))
 
 		if older.Recievers == newer.Recievers {
-		} else if older.Recievers == nil && newer.Recievers != nil {
-			return false
-		} else if older.Recievers != nil && newer.Recievers == nil {
-			return false
-		} else if !older.Recievers.Compare(newer.Recievers) {
-			return false
+		} else if older.Recievers == nil || newer.Recievers == nil {
+			ctx.report(at, Changed, older, newer)
+			return
+		} else {
+			older.Recievers.Diff(ctx, at.Child("recievers", ""), newer.Recievers)
 		}
 
-		if older.Params == newer.Params {
-		} else if older.Params == nil && newer.Params != nil {
-			return false
-		} else if older.Params != nil && newer.Params == nil {
-			return false
-		} else if !older.Params.Compare(newer.Params) {
-			return false
-		}
+		diffTypeParams(ctx, at, older.TypeParams, newer.TypeParams)
+		older.diffSignature(ctx, at, newer, ctx.options.FuncValues)
+	} else {
+		ctx.report(at, Changed, older, n)
+	}
+}
 
-		if older.Results == newer.Results {
-		} else if older.Results == nil && newer.Results != nil {
-			return false
-		} else if older.Results != nil && newer.Results == nil {
-			return false
-		} else if !older.Results.Compare(newer.Results) {
-			return false
-		}
+// diffSignature reports the changes in the parameters and results of the
+// function. Changes keeping calls of the function compiling, but changing
+// the type of its value, are reported with the given compatibility. These
+// include appending a variadic parameter and relaxing a parameter type to
+// an interface implemented by the former type.
+func (older *Func) diffSignature(ctx *DiffContext, at Location, newer *Func, compatibility Compatibility) {
+	newer = newer.renamed(recieverRenaming(older.Recievers, newer.Recievers))
 
-		return true
+	if older.Params == newer.Params {
+	} else if older.Params.callCompatible(ctx, at.Package, newer.Params) {
+		ctx.reportAs(at.Child("params", ""), Changed, compatibility, older.Params, newer.Params)
+	} else if older.Params == nil || newer.Params == nil {
+		ctx.report(at.Child("params", ""), Changed, older, newer)
+	} else {
+		older.Params.diffRelaxed(ctx, at.Child("params", ""), newer.Params, compatibility)
+	}
+
+	if older.Results == newer.Results {
+	} else if older.Results == nil || newer.Results == nil {
+		ctx.report(at.Child("results", ""), Changed, older, newer)
 	} else {
+		older.Results.diffNarrowed(ctx, at.Child("results", ""), newer.Results, compatibility)
+	}
+}
+
+// PointerReciever returns if the function is a method with a pointer reciever.
+func (f *Func) PointerReciever() bool {
+	if f.Recievers == nil || len(f.Recievers.Types) == 0 {
+		return false
+	}
+	switch t := f.Recievers.Types[0].(type) {
+	case *PointerType:
+		return true
+	case *SimpleType:
+		return strings.HasPrefix(t.Name, "*")
+	default:
 		return false
 	}
 }
+
+// signature renders the parameters and results of the function.
+func (f *Func) signature() string {
+	signature := f.Params.String()
+	if results := f.Results.String(); results != "" {
+		signature += " " + results
+	}
+	return signature
+}
+
+func (f *Func) String() string {
+	if f.Recievers != nil {
+		return "func " + f.Recievers.String() + " " + f.Name + f.signature()
+	}
+	return "func " + f.Name + f.TypeParams.String() + f.signature()
+}
@@ -0,0 +1,34 @@
+package cst
+
+// FuncType represents a function type node - func(int) error, etc...
+type FuncType struct {
+	Params  *Params
+	Results *Results
+}
+
+func (older *FuncType) Compare(n Node) bool {
+	if newer, ok := n.(*FuncType); ok {
+		if older.Params == nil || newer.Params == nil {
+			if older.Params != newer.Params {
+				return false
+			}
+		} else if !older.Params.Compare(newer.Params) {
+			return false
+		}
+
+		if older.Results == nil || newer.Results == nil {
+			return older.Results == newer.Results
+		}
+		return older.Results.Compare(newer.Results)
+	} else {
+		return false
+	}
+}
+
+func (t *FuncType) String() string {
+	signature := "func" + t.Params.String()
+	if results := t.Results.String(); results != "" {
+		signature += " " + results
+	}
+	return signature
+}
@@ -0,0 +1,185 @@
+package cst
+
+import (
+	"go/token"
+	"strings"
+)
+
+// method is a method of a method set along with the path of the package its
+// signature refers to.
+type method struct {
+	f   *Func
+	pkg string
+}
+
+// declaration resolves the type definition a type refers to in the project
+// or its dependencies, along with the path of the package declaring it.
+func (p *Project) declaration(pkg string, t Type) (*TypeDef, string) {
+	if def, defPkg := p.lookup(pkg, t); def != nil {
+		return def, defPkg
+	}
+	dependencies := &Project{Packages: p.Dependencies}
+	return dependencies.lookup(pkg, t)
+}
+
+// qualify returns a type with the aliases of the project replaced by their
+// targets and the named types of the project and its dependencies qualified
+// with their package.
+func (p *Project) qualify(pkg string, t Type) Type {
+	t = p.unalias(pkg, t)
+	if p.Dependencies != nil {
+		dependencies := &Project{Packages: p.Dependencies}
+		t = dependencies.unalias(pkg, t)
+	}
+	return t
+}
+
+// isInterface returns if a type is an interface type.
+func (p *Project) isInterface(pkg string, t Type) bool {
+	if p == nil {
+		return false
+	}
+	switch t := p.unalias(pkg, t).(type) {
+	case *Interface:
+		return true
+	case *SimpleType:
+		if t.Name == "any" {
+			return true
+		}
+	}
+	def, _ := p.declaration(pkg, p.unalias(pkg, t))
+	if def == nil {
+		return false
+	}
+	_, ok := def.Type.(*Interface)
+	return ok
+}
+
+// methods returns the method set of a type. It returns false if the method
+// set is not completely known, e.g. for types declared outside the project
+// when its dependencies are not recorded. Predeclared types and any have no
+// methods.
+func (p *Project) methods(pkg string, t Type) (map[string]method, bool) {
+	t = p.unalias(pkg, t)
+	if st, ok := t.(*SimpleType); ok && (st.Name == "any" || basicTypes[st.Name]) {
+		return map[string]method{}, true
+	}
+	if i, ok := t.(*Interface); ok {
+		set := p.methodSet(pkg, i)
+		if set.sealed || len(set.unresolved) > 0 || i.TypeSet != nil {
+			return nil, false
+		}
+		methods := map[string]method{}
+		for name, m := range set.methods {
+			methods[name] = method{m.Node.(*Func), originPackage(m.Origin, pkg)}
+		}
+		return methods, true
+	}
+
+	pointer := false
+	if pt, ok := t.(*PointerType); ok {
+		t, pointer = pt.Elem, true
+	}
+	def, defPkg := p.declaration(pkg, t)
+	if def == nil {
+		return nil, false
+	}
+	if i, ok := def.Type.(*Interface); ok {
+		if pointer {
+			return nil, false
+		}
+		return p.methods(defPkg, i)
+	}
+
+	// Methods with pointer recievers are not in the method set of values.
+	methods := map[string]method{}
+	for name, f := range def.Methods {
+		if pointer || !f.PointerReciever() {
+			methods[name] = method{f, defPkg}
+		}
+	}
+	for name, m := range p.promoted(defPkg, def) {
+		if f, ok := m.Node.(*Func); ok {
+			methods[name] = method{f, originPackage(m.Origin, defPkg)}
+		}
+	}
+	return methods, true
+}
+
+// basicTypes lists the predeclared types untyped constants convert to.
+var basicTypes = map[string]bool{
+	"bool": true, "string": true, "byte": true, "rune": true, "uintptr": true,
+	"int": true, "int8": true, "int16": true, "int32": true, "int64": true,
+	"uint": true, "uint8": true, "uint16": true, "uint32": true, "uint64": true,
+	"float32": true, "float64": true, "complex64": true, "complex128": true,
+}
+
+// basic returns the underlying type of a type if it is a predeclared basic
+// type, so that untyped constants convert to it, or nil otherwise.
+func (p *Project) basic(pkg string, t Type) Type {
+	visited := map[*TypeDef]bool{}
+	for {
+		t = p.unalias(pkg, t)
+		if st, ok := t.(*SimpleType); ok && basicTypes[st.Name] {
+			return st
+		}
+		def, defPkg := p.declaration(pkg, t)
+		if def == nil || def.Type == nil || visited[def] {
+			return nil
+		}
+		visited[def] = true
+		pkg, t = defPkg, def.Type
+	}
+}
+
+// originPackage returns the package path of a qualified member origin.
+func originPackage(origin, pkg string) string {
+	if i := strings.LastIndex(origin, "."); i >= 0 {
+		return origin[:i]
+	}
+	return pkg
+}
+
+// implements returns if values of type t, as declared in the project tp,
+// implement the interface type iface, as declared in the project ip, so
+// that they can be used where the interface is expected. Types with unknown
+// method sets implement no interface.
+func implements(pkg string, tp *Project, t Type, ip *Project, iface Type) bool {
+	if tp == nil || ip == nil || !ip.isInterface(pkg, iface) {
+		return false
+	}
+	required, ok := ip.methods(pkg, iface)
+	if !ok {
+		return false
+	}
+	provided, ok := tp.methods(pkg, t)
+	if !ok {
+		return false
+	}
+
+	for name, r := range required {
+		m, ok := provided[name]
+		if !ok || !token.IsExported(name) && m.pkg != r.pkg {
+			return false
+		}
+		if !sameTypes(ip, r.pkg, r.f.Params.types(), tp, m.pkg, m.f.Params.types()) ||
+			!sameTypes(ip, r.pkg, r.f.Results.types(), tp, m.pkg, m.f.Results.types()) {
+			return false
+		}
+	}
+	return true
+}
+
+// sameTypes returns if two lists of types, referred to in possibly
+// different packages and projects, are identical.
+func sameTypes(ap *Project, aPkg string, a []Type, bp *Project, bPkg string, b []Type) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !ap.qualify(aPkg, a[i]).Compare(bp.qualify(bPkg, b[i])) {
+			return false
+		}
+	}
+	return true
+}
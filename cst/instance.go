@@ -0,0 +1,27 @@
+package cst
+
+// Instance represents an instantiated generic type node - List[int], Map[K, V], etc...
+type Instance struct {
+	Type Type
+	Args []Type
+}
+
+func (older *Instance) Compare(n Node) bool {
+	if newer, ok := n.(*Instance); ok {
+		if !older.Type.Compare(newer.Type) || len(older.Args) != len(newer.Args) {
+			return false
+		}
+		for i, arg := range older.Args {
+			if !arg.Compare(newer.Args[i]) {
+				return false
+			}
+		}
+		return true
+	} else {
+		return false
+	}
+}
+
+func (t *Instance) String() string {
+	return t.Type.String() + "[" + joinTypes(t.Args) + "]"
+}
@@ -1,23 +1,184 @@
 package cst
 
+import "strings"
+
 // Interface represents an interface type node.
 type Interface struct {
 	Funcs map[string]*Func
+
+	// Embeds lists the embedded interfaces, whose methods are part of the
+	// method set of the interface.
+	Embeds []Type
+
+	// TypeSet restricts the types implementing a constraint interface.
+	TypeSet *Union
+
+	// Sealed marks interfaces that can not be implemented outside of their
+	// package, either because they have unexported methods or because they
+	// are explicitly marked with a //gocompat:sealed comment. Adding methods
+	// to sealed interfaces does not break compatibility.
+	Sealed bool
 }
 
 func (older *Interface) Compare(n Node) bool {
+	return equivalent(older, n)
+}
+
+func (older *Interface) Diff(ctx *DiffContext, at Location, n Node) {
 	if newer, ok := n.(*Interface); ok {
-		for name, sOlder := range older.Funcs {
-			if sNewer, ok := newer.Funcs[name]; ok {
-				if !sOlder.Compare(sNewer) {
-					return false
+		oSet := ctx.older.methodSet(at.Package, older)
+		nSet := ctx.newer.methodSet(at.Package, newer)
+
+		if !oSet.sealed && nSet.sealed {
+			ctx.report(at, Changed, older, newer)
+		}
+
+		// Interfaces with type sets are only used as constraints, so
+		// widening the type set is compatible.
+		switch {
+		case older.TypeSet == nil && newer.TypeSet == nil:
+		case older.TypeSet == nil:
+			ctx.report(at, Changed, older, newer)
+		case newer.TypeSet == nil || newer.TypeSet.covers(older.TypeSet):
+			if newer.TypeSet == nil || !older.TypeSet.Compare(newer.TypeSet) {
+				ctx.reportAs(at, Changed, Compatible, older, newer)
+			}
+		default:
+			ctx.report(at, Changed, older, newer)
+		}
+
+		for _, name := range sortedKeys(oSet.methods) {
+			sOlder := oSet.methods[name]
+			child := at.Child(name, sOlder.pos()).Of("method")
+			if sNewer, ok := nSet.methods[name]; ok {
+				// Changes of exported embedded interfaces are reported
+				// where they are declared. Any change of a method signature
+				// breaks the implementations of the interface.
+				if sOlder.Origin == "" || sOlder.Origin != sNewer.Origin || !exportedOrigin(sOlder.Origin) {
+					sOlder.Node.(*Func).diffSignature(ctx, child, sNewer.Node.(*Func), Breaking)
 				}
-			} else {
-				return false
+			} else if !nSet.embeds[sOlder.Origin] || !exportedOrigin(sOlder.Origin) {
+				ctx.report(child, Removed, sOlder.Node, nil)
+			}
+		}
+
+		for _, t := range oSet.unresolved {
+			if !containsType(nSet.unresolved, t) {
+				ctx.report(at.Child(t.String(), "").Of("embed"), Removed, t, nil)
+			}
+		}
+
+		// Implementations of open interfaces miss any newly added method.
+		compatibility := Breaking
+		if oSet.sealed {
+			compatibility = Compatible
+		}
+		for _, name := range sortedKeys(nSet.methods) {
+			sNewer := nSet.methods[name]
+			if _, ok := oSet.methods[name]; !ok && (!oSet.embeds[sNewer.Origin] || !exportedOrigin(sNewer.Origin)) {
+				child := at.Child(name, sNewer.pos()).Of("method")
+				ctx.reportAs(child, Added, compatibility, nil, sNewer.Node)
+			}
+		}
+
+		for _, t := range nSet.unresolved {
+			if !containsType(oSet.unresolved, t) {
+				ctx.reportAs(at.Child(t.String(), "").Of("embed"), Added, compatibility, nil, t)
 			}
 		}
-		return true
 	} else {
-		return false
+		ctx.report(at, Changed, older, n)
+	}
+}
+
+func (i *Interface) String() string {
+	methods := []string{}
+	for _, t := range i.Embeds {
+		methods = append(methods, t.String())
+	}
+	if i.TypeSet != nil {
+		methods = append(methods, i.TypeSet.String())
+	}
+	for _, name := range sortedKeys(i.Funcs) {
+		methods = append(methods, name+i.Funcs[name].signature())
+	}
+	if i.Sealed {
+		methods = append(methods, "// sealed")
+	}
+	return "interface{" + strings.Join(methods, "; ") + "}"
+}
+
+// methodSet is the flattened method set of an interface.
+type methodSet struct {
+	methods map[string]member
+
+	// Origins of the embedded interfaces resolved in the project.
+	embeds map[string]bool
+
+	// Embedded types which are not declared in the project.
+	unresolved []Type
+
+	// Sealed is set when the interface or any interface it embeds is sealed.
+	sealed bool
+
+	// TypeSets is set when any embedded interface restricts its type set.
+	typeSets bool
+}
+
+// methodSet flattens the methods of an interface and the interfaces it
+// embeds. Methods declared by the interface itself have no origin, while
+// promoted methods originate from the directly embedded interface.
+func (p *Project) methodSet(pkg string, i *Interface) *methodSet {
+	set := &methodSet{
+		methods: map[string]member{},
+		embeds:  map[string]bool{},
+		sealed:  i.Sealed,
+	}
+	for name, f := range i.Funcs {
+		set.methods[name] = member{f, "", false}
+	}
+	p.embedInterfaces(set, pkg, i, "", map[*Interface]bool{i: true})
+	return set
+}
+
+func (p *Project) embedInterfaces(set *methodSet, pkg string, i *Interface, origin string, visited map[*Interface]bool) {
+	for _, t := range i.Embeds {
+		def, defPkg := p.lookup(pkg, t)
+		var embedded *Interface
+		if def != nil {
+			embedded, _ = def.Type.(*Interface)
+		}
+		if embedded == nil {
+			set.unresolved = append(set.unresolved, t)
+			continue
+		}
+		if visited[embedded] {
+			continue
+		}
+		visited[embedded] = true
+
+		eOrigin := origin
+		if eOrigin == "" {
+			eOrigin = defPkg + "." + def.Name
+			set.embeds[eOrigin] = true
+		}
+		for name, f := range embedded.Funcs {
+			if _, ok := set.methods[name]; !ok {
+				set.methods[name] = member{f, eOrigin, false}
+			}
+		}
+		set.sealed = set.sealed || embedded.Sealed
+		set.typeSets = set.typeSets || embedded.TypeSet != nil
+		p.embedInterfaces(set, defPkg, embedded, eOrigin, visited)
+	}
+}
+
+// containsType returns if a list of types contains an equal type.
+func containsType(types []Type, t Type) bool {
+	for _, other := range types {
+		if other.Compare(t) {
+			return true
+		}
 	}
+	return false
 }
@@ -0,0 +1,186 @@
+package cst
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// nodeKey is the JSON object key holding the name of a node stored behind
+// the Node or Type interfaces.
+const nodeKey = "Node"
+
+// MarshalJSON encodes a project in a canonical, indented JSON form. Object
+// keys are sorted and empty values are omitted, so that encoding the same
+// project always yields the same output.
+func MarshalJSON(p *Project) ([]byte, error) {
+	encoded, err := json.MarshalIndent(encodeValue(reflect.ValueOf(p)), "", "\t")
+	if err != nil {
+		return nil, err
+	}
+	return append(encoded, '\n'), nil
+}
+
+// UnmarshalJSON decodes a project encoded by MarshalJSON.
+func UnmarshalJSON(data []byte) (*Project, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var raw interface{}
+	if err := decoder.Decode(&raw); err != nil {
+		return nil, err
+	}
+
+	value, err := decodeValue(raw, reflect.TypeOf(&Project{}))
+	if err != nil {
+		return nil, err
+	}
+	return value.Interface().(*Project), nil
+}
+
+func encodeValue(v reflect.Value) interface{} {
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		encoded, ok := encodeValue(v.Elem()).(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		encoded[nodeKey] = v.Elem().Elem().Type().Name()
+		return encoded
+	case reflect.Ptr:
+		if v.IsNil() {
+			return nil
+		}
+		return encodeValue(v.Elem())
+	case reflect.Struct:
+		encoded := map[string]interface{}{}
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if field.PkgPath != "" || v.Field(i).IsZero() {
+				continue
+			}
+			encoded[field.Name] = encodeValue(v.Field(i))
+		}
+		return encoded
+	case reflect.Map:
+		encoded := map[string]interface{}{}
+		for _, key := range v.MapKeys() {
+			encoded[key.String()] = encodeValue(v.MapIndex(key))
+		}
+		return encoded
+	case reflect.Slice:
+		encoded := make([]interface{}, v.Len())
+		for i := range encoded {
+			encoded[i] = encodeValue(v.Index(i))
+		}
+		return encoded
+	default:
+		return v.Interface()
+	}
+}
+
+func decodeValue(raw interface{}, t reflect.Type) (reflect.Value, error) {
+	value := reflect.New(t).Elem()
+	if raw == nil {
+		return value, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Interface:
+		object, ok := raw.(map[string]interface{})
+		if !ok {
+			return value, fmt.Errorf("expected node object, got %v", raw)
+		}
+		name, _ := object[nodeKey].(string)
+		nodeType, ok := nodeTypes[name]
+		if !ok {
+			return value, fmt.Errorf("unknown node %q", name)
+		}
+		node, err := decodeValue(raw, reflect.PtrTo(nodeType))
+		if err != nil {
+			return value, err
+		}
+		if !node.Type().Implements(t) {
+			return value, fmt.Errorf("node %q is not a %s", name, t.Name())
+		}
+		value.Set(node)
+	case reflect.Ptr:
+		elem, err := decodeValue(raw, t.Elem())
+		if err != nil {
+			return value, err
+		}
+		value.Set(reflect.New(t.Elem()))
+		value.Elem().Set(elem)
+	case reflect.Struct:
+		object, ok := raw.(map[string]interface{})
+		if !ok {
+			return value, fmt.Errorf("expected %s object, got %v", t.Name(), raw)
+		}
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if field.PkgPath != "" {
+				continue
+			}
+			fieldValue, err := decodeValue(object[field.Name], field.Type)
+			if err != nil {
+				return value, err
+			}
+			value.Field(i).Set(fieldValue)
+		}
+	case reflect.Map:
+		object, ok := raw.(map[string]interface{})
+		if !ok {
+			return value, fmt.Errorf("expected map, got %v", raw)
+		}
+		value.Set(reflect.MakeMap(t))
+		for key, rawElem := range object {
+			elem, err := decodeValue(rawElem, t.Elem())
+			if err != nil {
+				return value, err
+			}
+			value.SetMapIndex(reflect.ValueOf(key).Convert(t.Key()), elem)
+		}
+	case reflect.Slice:
+		list, ok := raw.([]interface{})
+		if !ok {
+			return value, fmt.Errorf("expected list, got %v", raw)
+		}
+		value.Set(reflect.MakeSlice(t, len(list), len(list)))
+		for i, rawElem := range list {
+			elem, err := decodeValue(rawElem, t.Elem())
+			if err != nil {
+				return value, err
+			}
+			value.Index(i).Set(elem)
+		}
+	case reflect.String:
+		s, ok := raw.(string)
+		if !ok {
+			return value, fmt.Errorf("expected string, got %v", raw)
+		}
+		value.SetString(s)
+	case reflect.Bool:
+		b, ok := raw.(bool)
+		if !ok {
+			return value, fmt.Errorf("expected bool, got %v", raw)
+		}
+		value.SetBool(b)
+	case reflect.Int:
+		n, ok := raw.(json.Number)
+		if !ok {
+			return value, fmt.Errorf("expected number, got %v", raw)
+		}
+		i, err := n.Int64()
+		if err != nil {
+			return value, err
+		}
+		value.SetInt(i)
+	default:
+		return value, fmt.Errorf("unsupported type %s", t)
+	}
+	return value, nil
+}
@@ -0,0 +1,19 @@
+package cst
+
+// MapType represents a map type node - map[string]int, etc...
+type MapType struct {
+	Key   Type
+	Value Type
+}
+
+func (older *MapType) Compare(n Node) bool {
+	if newer, ok := n.(*MapType); ok {
+		return older.Key.Compare(newer.Key) && older.Value.Compare(newer.Value)
+	} else {
+		return false
+	}
+}
+
+func (t *MapType) String() string {
+	return "map[" + t.Key.String() + "]" + t.Value.String()
+}
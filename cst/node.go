@@ -6,4 +6,17 @@ type Node interface {
 	// Compare performs semantically-specific comparison with another node.
 	// Return true if both nodes are equal.
 	Compare(Node) bool
+
+	// String renders the node in Go-like syntax.
+	String() string
+}
+
+// Differ is implemented by nodes that are able to report each individual
+// change between two versions of themselves instead of a single verdict.
+type Differ interface {
+	Node
+
+	// Diff walks the node and a newer version of it, reporting every change
+	// found to the context. at identifies the node within the project.
+	Diff(ctx *DiffContext, at Location, n Node)
 }
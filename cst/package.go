@@ -4,21 +4,104 @@ package cst
 type Package struct {
 	Name  string
 	Nodes map[string]Node
+
+	// Path is the import path of the package.
+	Path string
+
+	// Unexported holds the definitions of unexported types, keyed by name.
+	// They are not part of the interface, but exported members are promoted
+	// from them and they decide the comparability of the types using them.
+	Unexported map[string]Node
+}
+
+// declaration returns the declaration of a name, exported or not.
+func (p *Package) declaration(name string) Node {
+	if n, ok := p.Nodes[name]; ok {
+		return n
+	}
+	return p.Unexported[name]
 }
 
 func (older *Package) Compare(n Node) bool {
+	return equivalent(older, n)
+}
+
+func (older *Package) Diff(ctx *DiffContext, at Location, n Node) {
 	if newer, ok := n.(*Package); ok {
-		for name, sOlder := range older.Nodes {
+		if older.Name != newer.Name {
+			ctx.report(at, Changed, older, newer)
+		}
+
+		for _, name := range sortedKeys(older.Nodes) {
+			sOlder := older.Nodes[name]
+			child := at.Child(name, position(sOlder)).Of(symbolKind(sOlder))
 			if sNewer, ok := newer.Nodes[name]; ok {
-				if !sOlder.Compare(sNewer) {
-					return false
+				if d, ok := sOlder.(Differ); ok {
+					d.Diff(ctx, child, sNewer)
+				} else if !sOlder.Compare(sNewer) {
+					ctx.report(child, Changed, sOlder, sNewer)
 				}
 			} else {
-				return false
+				ctx.report(child, Removed, sOlder, nil)
+			}
+		}
+
+		for _, name := range sortedKeys(newer.Nodes) {
+			sNewer := newer.Nodes[name]
+			if _, ok := older.Nodes[name]; !ok {
+				child := at.Child(name, position(sNewer)).Of(symbolKind(sNewer))
+				ctx.reportAs(child, Added, Compatible, nil, sNewer)
 			}
 		}
-		return true
 	} else {
-		return false
+		ctx.report(at, Changed, older, n)
+	}
+}
+
+func (p *Package) String() string {
+	if p.Path != "" && p.Path != p.Name {
+		return "package " + p.Name + " // import \"" + p.Path + "\""
+	}
+	return "package " + p.Name
+}
+
+// symbolKind returns the kind of a declaration node, as used in reports.
+func symbolKind(n Node) string {
+	switch n := n.(type) {
+	case *TypeDef, *Alias:
+		return "type"
+	case *Func:
+		if n.Recievers != nil {
+			return "method"
+		}
+		return "func"
+	case *Var:
+		return "var"
+	case *Const:
+		return "const"
+	case *Field:
+		return "field"
+	default:
+		return ""
+	}
+}
+
+// position returns the position of a declaration node, if it has one.
+func position(n Node) string {
+	switch n := n.(type) {
+	case *TypeDef:
+		return n.Pos
+	case *Alias:
+		return n.Pos
+	case *Func:
+		return n.Pos
+	case *Var:
+		return n.Pos
+	case *Const:
+		return n.Pos
+	case *Field:
+		return n.Pos
+	default:
+		return ""
 	}
 }
@@ -1,24 +1,112 @@
 package cst
 
+import "fmt"
+
 // Params represents function parameters node.
 type Params struct {
 	Types []Type
 }
 
 func (older *Params) Compare(n Node) bool {
+	return equivalent(older, n)
+}
+
+func (older *Params) Diff(ctx *DiffContext, at Location, n Node) {
 	if newer, ok := n.(*Params); ok {
 		if len(older.Types) != len(newer.Types) {
-			return false
+			ctx.report(at, Changed, older, newer)
+			return
 		}
 
 		for i, oType := range older.Types {
 			nType := newer.Types[i]
-			if !oType.Compare(nType) {
-				return false
-			}
+			diffType(ctx, at.Child(fmt.Sprintf("%d", i), ""), oType, nType)
 		}
-		return true
 	} else {
+		ctx.report(at, Changed, older, n)
+	}
+}
+
+// diffRelaxed compares the parameters like Diff, but accepts parameter
+// types relaxed to interfaces implemented by their former types, as calls
+// keep compiling. Relaxed parameters are reported with the given
+// compatibility.
+func (older *Params) diffRelaxed(ctx *DiffContext, at Location, newer *Params, compatibility Compatibility) {
+	if len(older.Types) != len(newer.Types) {
+		ctx.report(at, Changed, older, newer)
+		return
+	}
+
+	for i, oType := range older.Types {
+		nType := newer.Types[i]
+		child := at.Child(fmt.Sprintf("%d", i), "")
+		if !ctx.sameType(at.Package, oType, nType) && implements(at.Package, ctx.older, oType, ctx.newer, nType) {
+			// Untyped constants convert to types with a basic underlying
+			// type. Passed as interfaces, they get their default type, which
+			// has no methods, so they may no longer implement the interface.
+			note := oType.String() + " implements " + nType.String()
+			if b := ctx.older.basic(at.Package, oType); b != nil && !implements(at.Package, ctx.older, b, ctx.newer, nType) {
+				note += ", but untyped constants can not be passed anymore"
+				ctx.explain(child, Changed, Breaking, oType, nType, note)
+			} else {
+				ctx.explain(child, Changed, compatibility, oType, nType, note)
+			}
+		} else {
+			diffType(ctx, child, oType, nType)
+		}
+	}
+}
+
+// callCompatible returns if every call with the older parameters compiles
+// with the newer ones, which holds when a variadic parameter is appended or
+// the last parameter becomes variadic.
+func (older *Params) callCompatible(ctx *DiffContext, pkg string, newer *Params) bool {
+	var oTypes, nTypes []Type
+	if older != nil {
+		oTypes = older.Types
+	}
+	if newer != nil {
+		nTypes = newer.Types
+	}
+	if len(nTypes) == 0 {
 		return false
 	}
+
+	elem, ok := variadicElem(nTypes[len(nTypes)-1])
+	if !ok {
+		return false
+	}
+	switch len(oTypes) {
+	case len(nTypes) - 1:
+	case len(nTypes):
+		last := oTypes[len(oTypes)-1]
+		if _, ok := variadicElem(last); ok || !ctx.sameType(pkg, last, elem) {
+			return false
+		}
+		oTypes = oTypes[:len(oTypes)-1]
+	default:
+		return false
+	}
+
+	for i, oType := range oTypes {
+		if !ctx.sameType(pkg, oType, nTypes[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// types returns the parameter types, which are none for nil parameters.
+func (p *Params) types() []Type {
+	if p == nil {
+		return nil
+	}
+	return p.Types
+}
+
+func (p *Params) String() string {
+	if p == nil {
+		return "()"
+	}
+	return "(" + joinTypes(p.Types) + ")"
 }
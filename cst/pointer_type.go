@@ -0,0 +1,19 @@
+package cst
+
+// PointerType represents a pointer to a composite type node - *[]byte, *struct{}, etc...
+// Pointers to named types are kept as simple types for brevity.
+type PointerType struct {
+	Elem Type
+}
+
+func (older *PointerType) Compare(n Node) bool {
+	if newer, ok := n.(*PointerType); ok {
+		return older.Elem.Compare(newer.Elem)
+	} else {
+		return false
+	}
+}
+
+func (t *PointerType) String() string {
+	return "*" + t.Elem.String()
+}
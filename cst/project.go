@@ -3,25 +3,49 @@ package cst
 // Project represents a Go program with its constituent elements.
 type Project struct {
 	Packages map[string]*Package
+
+	// Dependencies holds the method sets of types declared outside of the
+	// project and referred to by its interface, keyed by package path. They
+	// are not part of the interface and are only recorded by the go/types
+	// based builder.
+	Dependencies map[string]*Package
 }
 
 func (older *Project) Compare(n Node) bool {
+	return equivalent(older, n)
+}
+
+func (older *Project) Diff(ctx *DiffContext, at Location, n Node) {
 	if newer, ok := n.(*Project); ok {
-		if len(older.Packages) != len(newer.Packages) {
-			return false
-		}
+		ctx.older, ctx.newer = older, newer
 
-		for name, sOlder := range older.Packages {
+		for _, name := range sortedKeys(older.Packages) {
+			sOlder := older.Packages[name]
+			child := packageLocation(at, name)
 			if sNewer, ok := newer.Packages[name]; ok {
-				if !sOlder.Compare(sNewer) {
-					return false
-				}
+				sOlder.Diff(ctx, child, sNewer)
 			} else {
-				return false
+				ctx.report(child, Removed, sOlder, nil)
+			}
+		}
+
+		for _, name := range sortedKeys(newer.Packages) {
+			if _, ok := older.Packages[name]; !ok {
+				ctx.reportAs(packageLocation(at, name), Added, Compatible, nil, newer.Packages[name])
 			}
 		}
-		return true
 	} else {
-		return false
+		ctx.report(at, Changed, older, n)
 	}
 }
+
+// packageLocation returns the location of a package within the project.
+func packageLocation(at Location, path string) Location {
+	child := at.Child(path, "").Of("package")
+	child.Package = path
+	return child
+}
+
+func (p *Project) String() string {
+	return "project"
+}
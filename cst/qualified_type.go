@@ -0,0 +1,20 @@
+package cst
+
+// QualifiedType represents a type node declared in another package - io.Reader,
+// time.Duration, etc... The package is identified by its full import path.
+type QualifiedType struct {
+	Path string
+	Name string
+}
+
+func (older *QualifiedType) Compare(n Node) bool {
+	if newer, ok := n.(*QualifiedType); ok {
+		return older.Path == newer.Path && older.Name == newer.Name
+	} else {
+		return false
+	}
+}
+
+func (t *QualifiedType) String() string {
+	return t.Path + "." + t.Name
+}
@@ -1,20 +1,32 @@
 package cst
 
+import "fmt"
+
 // Recievers represents function recievers node.
 type Recievers struct {
 	Types []Type
 }
 
 func (older *Recievers) Compare(n Node) bool {
+	return equivalent(older, n)
+}
+
+func (older *Recievers) Diff(ctx *DiffContext, at Location, n Node) {
 	if newer, ok := n.(*Recievers); ok {
+		if len(older.Types) != len(newer.Types) {
+			ctx.report(at, Changed, older, newer)
+			return
+		}
+
 		for i, oType := range older.Types {
 			nType := newer.Types[i]
-			if !oType.Compare(nType) {
-				return false
-			}
+			diffType(ctx, at.Child(fmt.Sprintf("%d", i), ""), oType, nType)
 		}
-		return true
 	} else {
-		return false
+		ctx.report(at, Changed, older, n)
 	}
 }
+
+func (r *Recievers) String() string {
+	return "(" + joinTypes(r.Types) + ")"
+}
@@ -0,0 +1,49 @@
+package cst
+
+import (
+	"encoding/gob"
+	"reflect"
+)
+
+// nodeTypes maps the names of concrete node types to their types so that
+// nodes stored behind the Node and Type interfaces can be decoded.
+var nodeTypes = map[string]reflect.Type{}
+
+// register makes node types available to the gob and JSON index encodings.
+func register(nodes ...Node) {
+	for _, n := range nodes {
+		gob.Register(n)
+		t := reflect.TypeOf(n).Elem()
+		nodeTypes[t.Name()] = t
+	}
+}
+
+func init() {
+	register(
+		&Alias{},
+		&ArrayType{},
+		&ChanType{},
+		&Const{},
+		&Field{},
+		&Func{},
+		&FuncType{},
+		&Instance{},
+		&Interface{},
+		&MapType{},
+		&Package{},
+		&Params{},
+		&PointerType{},
+		&Project{},
+		&QualifiedType{},
+		&Recievers{},
+		&Results{},
+		&SimpleType{},
+		&SliceType{},
+		&Struct{},
+		&TypeDef{},
+		&TypeParams{},
+		&Union{},
+		&Var{},
+		&VariadicType{},
+	)
+}
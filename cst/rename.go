@@ -0,0 +1,268 @@
+package cst
+
+import "strings"
+
+// typeParamRenaming maps the names of the type parameters of a newer
+// declaration to the names of the parameters at the same positions in the
+// older one. Renaming a type parameter is compatible, so the newer
+// declaration is compared with its parameters renamed.
+func typeParamRenaming(older, newer *TypeParams) map[string]string {
+	if older == nil || newer == nil || len(older.Params) != len(newer.Params) {
+		return nil
+	}
+	names := map[string]string{}
+	for i, oParam := range older.Params {
+		if nParam := newer.Params[i]; nParam.Name != oParam.Name {
+			names[nParam.Name] = oParam.Name
+		}
+	}
+	return names
+}
+
+// recieverRenaming maps the names of the type parameters of a newer method
+// reciever, e.g. *List[E], to the names used by the older reciever.
+func recieverRenaming(older, newer *Recievers) map[string]string {
+	if older == nil || newer == nil || len(older.Types) == 0 || len(newer.Types) == 0 {
+		return nil
+	}
+	oInstance, ok := recieverInstance(older.Types[0])
+	if !ok {
+		return nil
+	}
+	nInstance, ok := recieverInstance(newer.Types[0])
+	if !ok || len(oInstance.Args) != len(nInstance.Args) {
+		return nil
+	}
+
+	names := map[string]string{}
+	for i, oArg := range oInstance.Args {
+		oName, ok := oArg.(*SimpleType)
+		if !ok {
+			return nil
+		}
+		nName, ok := nInstance.Args[i].(*SimpleType)
+		if !ok {
+			return nil
+		}
+		if nName.Name != oName.Name {
+			names[nName.Name] = oName.Name
+		}
+	}
+	return names
+}
+
+// recieverInstance returns the instance a generic method is declared on.
+func recieverInstance(t Type) (*Instance, bool) {
+	if p, ok := t.(*PointerType); ok {
+		t = p.Elem
+	}
+	instance, ok := t.(*Instance)
+	return instance, ok
+}
+
+// renamed returns the function with the type parameters of its signature
+// renamed.
+func (f *Func) renamed(names map[string]string) *Func {
+	if len(names) == 0 {
+		return f
+	}
+	renamed := f.replaced(renaming(names))
+	renamed.TypeParams = f.TypeParams.renamed(names)
+	return renamed
+}
+
+// replaced returns the function with the types of its signature replaced.
+func (f *Func) replaced(replace func(Type) Type) *Func {
+	replaced := *f
+	replaced.TypeParams = f.TypeParams.replaced(replace)
+	if f.Recievers != nil {
+		replaced.Recievers = &Recievers{replaceTypes(f.Recievers.Types, replace)}
+	}
+	if f.Params != nil {
+		replaced.Params = &Params{replaceTypes(f.Params.Types, replace)}
+	}
+	if f.Results != nil {
+		replaced.Results = &Results{replaceTypes(f.Results.Types, replace)}
+	}
+	return &replaced
+}
+
+// renamed returns the type parameters with their names and constraints
+// renamed.
+func (tp *TypeParams) renamed(names map[string]string) *TypeParams {
+	if tp == nil {
+		return nil
+	}
+	renamed := &TypeParams{}
+	for _, param := range tp.Params {
+		name := param.Name
+		if to, ok := names[name]; ok {
+			name = to
+		}
+		renamed.Params = append(renamed.Params, &TypeParam{
+			Name:       name,
+			Constraint: renameType(param.Constraint, names),
+		})
+	}
+	return renamed
+}
+
+// replaced returns the type parameters with their constraints replaced.
+func (tp *TypeParams) replaced(replace func(Type) Type) *TypeParams {
+	if tp == nil {
+		return nil
+	}
+	replaced := &TypeParams{}
+	for _, param := range tp.Params {
+		replaced.Params = append(replaced.Params, &TypeParam{
+			Name:       param.Name,
+			Constraint: replaceType(param.Constraint, replace),
+		})
+	}
+	return replaced
+}
+
+// renamed returns the type definition with its type parameters renamed.
+// Methods declare their own type parameters and are renamed when compared.
+func (t *TypeDef) renamed(names map[string]string) *TypeDef {
+	if len(names) == 0 {
+		return t
+	}
+	renamed := *t
+	renamed.TypeParams = t.TypeParams.renamed(names)
+	if t.Type != nil {
+		renamed.Type = renameType(t.Type, names)
+	}
+	return &renamed
+}
+
+func renameTypes(types []Type, names map[string]string) []Type {
+	return replaceTypes(types, renaming(names))
+}
+
+// renameType returns a type with the given type names replaced.
+func renameType(t Type, names map[string]string) Type {
+	return replaceType(t, renaming(names))
+}
+
+// renaming replaces simple types with the given names.
+func renaming(names map[string]string) func(Type) Type {
+	return func(t Type) Type {
+		st, ok := t.(*SimpleType)
+		if !ok {
+			return t
+		}
+		// Type parameters may be prefixed, e.g. *T or ...T.
+		name := strings.TrimLeft(st.Name, "*.")
+		if to, ok := names[name]; ok {
+			return &SimpleType{st.Name[:len(st.Name)-len(name)] + to}
+		}
+		return t
+	}
+}
+
+func replaceTypes(types []Type, replace func(Type) Type) []Type {
+	replaced := make([]Type, len(types))
+	for i, t := range types {
+		replaced[i] = replaceType(t, replace)
+	}
+	return replaced
+}
+
+// replaceType returns a type with the types for which replace returns
+// another type replaced.
+func replaceType(t Type, replace func(Type) Type) Type {
+	if replaced := replace(t); replaced != t {
+		return replaced
+	}
+	switch t := t.(type) {
+	case *PointerType:
+		return &PointerType{Elem: replaceType(t.Elem, replace)}
+	case *VariadicType:
+		return &VariadicType{Elem: replaceType(t.Elem, replace)}
+	case *SliceType:
+		return &SliceType{Elem: replaceType(t.Elem, replace)}
+	case *ArrayType:
+		return &ArrayType{Len: t.Len, Elem: replaceType(t.Elem, replace)}
+	case *MapType:
+		return &MapType{Key: replaceType(t.Key, replace), Value: replaceType(t.Value, replace)}
+	case *ChanType:
+		return &ChanType{Dir: t.Dir, Elem: replaceType(t.Elem, replace)}
+	case *Instance:
+		return &Instance{Type: replaceType(t.Type, replace), Args: replaceTypes(t.Args, replace)}
+	case *FuncType:
+		funcType := &FuncType{}
+		if t.Params != nil {
+			funcType.Params = &Params{replaceTypes(t.Params.Types, replace)}
+		}
+		if t.Results != nil {
+			funcType.Results = &Results{replaceTypes(t.Results.Types, replace)}
+		}
+		return funcType
+	case *Union:
+		union := &Union{}
+		for _, term := range t.Terms {
+			union.Terms = append(union.Terms, &Term{Tilde: term.Tilde, Type: replaceType(term.Type, replace)})
+		}
+		return union
+	case *Struct:
+		fields := map[string]*Field{}
+		for name, field := range t.Fields {
+			replaced := *field
+			replaced.Type = replaceType(field.Type, replace)
+			fields[name] = &replaced
+		}
+		return &Struct{Fields: fields}
+	case *Interface:
+		i := *t
+		i.Funcs = map[string]*Func{}
+		for name, f := range t.Funcs {
+			i.Funcs[name] = f.replaced(replace)
+		}
+		i.Embeds = replaceTypes(t.Embeds, replace)
+		if t.TypeSet != nil {
+			i.TypeSet = replaceType(t.TypeSet, replace).(*Union)
+		}
+		return &i
+	default:
+		return t
+	}
+}
+
+// Unqualify replaces the references to the given names of the package with
+// the path by references to the types declared by the package itself.
+// Builders use it for names which turn out to be declared by the package
+// after being attributed to a dot import.
+func (p *Package) Unqualify(path string, names map[string]bool) {
+	replace := func(t Type) Type {
+		if q, ok := t.(*QualifiedType); ok && q.Path == path && names[q.Name] {
+			return &SimpleType{q.Name}
+		}
+		return t
+	}
+	for _, nodes := range []map[string]Node{p.Nodes, p.Unexported} {
+		for _, node := range nodes {
+			switch n := node.(type) {
+			case *Func:
+				*n = *n.replaced(replace)
+			case *TypeDef:
+				n.TypeParams = n.TypeParams.replaced(replace)
+				if n.Type != nil {
+					n.Type = replaceType(n.Type, replace)
+				}
+				for _, method := range n.Methods {
+					*method = *method.replaced(replace)
+				}
+			case *Alias:
+				n.TypeParams = n.TypeParams.replaced(replace)
+				n.Type = replaceType(n.Type, replace)
+			case *Var:
+				n.Type = replaceType(n.Type, replace)
+			case *Const:
+				if n.Type != nil {
+					n.Type = replaceType(n.Type, replace)
+				}
+			}
+		}
+	}
+}
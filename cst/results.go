@@ -1,20 +1,74 @@
 package cst
 
+import "fmt"
+
 // Results represents function results node.
 type Results struct {
 	Types []Type
 }
 
 func (older *Results) Compare(n Node) bool {
+	return equivalent(older, n)
+}
+
+func (older *Results) Diff(ctx *DiffContext, at Location, n Node) {
 	if newer, ok := n.(*Results); ok {
+		if len(older.Types) != len(newer.Types) {
+			ctx.report(at, Changed, older, newer)
+			return
+		}
+
 		for i, oType := range older.Types {
 			nType := newer.Types[i]
-			if !oType.Compare(nType) {
-				return false
-			}
+			diffType(ctx, at.Child(fmt.Sprintf("%d", i), ""), oType, nType)
 		}
-		return true
 	} else {
-		return false
+		ctx.report(at, Changed, older, n)
+	}
+}
+
+// diffNarrowed compares the results like Diff, but accepts result types
+// narrowed to interfaces implementing their former interface types, as the
+// results keep being usable as before. Variables declared with the results
+// no longer accept values of the former type though, so narrowed results
+// are potentially breaking, or breaking along with function values.
+func (older *Results) diffNarrowed(ctx *DiffContext, at Location, newer *Results, compatibility Compatibility) {
+	if len(older.Types) != len(newer.Types) {
+		ctx.report(at, Changed, older, newer)
+		return
+	}
+
+	if compatibility != Breaking {
+		compatibility = PotentiallyBreaking
+	}
+	for i, oType := range older.Types {
+		nType := newer.Types[i]
+		child := at.Child(fmt.Sprintf("%d", i), "")
+		if !ctx.sameType(at.Package, oType, nType) &&
+			ctx.newer.isInterface(at.Package, nType) &&
+			implements(at.Package, ctx.newer, nType, ctx.older, oType) {
+			note := nType.String() + " implements " + oType.String()
+			ctx.explain(child, Changed, compatibility, oType, nType, note)
+		} else {
+			diffType(ctx, child, oType, nType)
+		}
+	}
+}
+
+// types returns the result types, which are none for nil results.
+func (r *Results) types() []Type {
+	if r == nil {
+		return nil
+	}
+	return r.Types
+}
+
+func (r *Results) String() string {
+	if r == nil || len(r.Types) == 0 {
+		return ""
+	}
+	if len(r.Types) == 1 {
+		return r.Types[0].String()
 	}
+	return "(" + joinTypes(r.Types) + ")"
 }
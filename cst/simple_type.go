@@ -1,5 +1,15 @@
 package cst
 
+// InvalidType is the name of types which could not be type checked, e.g.
+// types of packages which could not be imported. Invalid types are never
+// equal, so that the symbols using them are reported as changed instead of
+// being silently considered compatible.
+const InvalidType = "invalid type"
+
+// UnknownType is the name of the types of vars which can not be inferred
+// from the syntax, e.g. var A = f(). Unknown types are not compared.
+const UnknownType = "unknown type"
+
 // SimpleType represents atomic type node - int, string, float64, etc...
 type SimpleType struct {
 	Name string
@@ -7,8 +17,12 @@ type SimpleType struct {
 
 func (older *SimpleType) Compare(n Node) bool {
 	if newer, ok := n.(*SimpleType); ok {
-		return older.Name == newer.Name
+		return older.Name == newer.Name && older.Name != InvalidType
 	} else {
 		return false
 	}
 }
+
+func (t *SimpleType) String() string {
+	return t.Name
+}
@@ -0,0 +1,18 @@
+package cst
+
+// SliceType represents a slice type node - []byte, []string, etc...
+type SliceType struct {
+	Elem Type
+}
+
+func (older *SliceType) Compare(n Node) bool {
+	if newer, ok := n.(*SliceType); ok {
+		return older.Elem.Compare(newer.Elem)
+	} else {
+		return false
+	}
+}
+
+func (t *SliceType) String() string {
+	return "[]" + t.Elem.String()
+}
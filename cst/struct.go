@@ -1,23 +1,63 @@
 package cst
 
+import (
+	"go/token"
+	"strings"
+)
+
 // Struct represents a struct type node.
 type Struct struct {
 	Fields map[string]*Field
 }
 
 func (older *Struct) Compare(n Node) bool {
+	return equivalent(older, n)
+}
+
+func (older *Struct) Diff(ctx *DiffContext, at Location, n Node) {
 	if newer, ok := n.(*Struct); ok {
-		for name, sOlder := range older.Fields {
+		for _, name := range sortedKeys(older.Fields) {
+			sOlder := older.Fields[name]
+			child := at.Child(name, sOlder.Pos).Of("field")
 			if sNewer, ok := newer.Fields[name]; ok {
-				if !sOlder.Compare(sNewer) {
-					return false
-				}
+				sOlder.Diff(ctx, child, sNewer)
 			} else {
-				return false
+				ctx.report(child, Removed, sOlder, nil)
+			}
+		}
+
+		// Unkeyed composite literals list every field, so they can only be
+		// written outside of the package when all fields are exported.
+		compatibility := Compatible
+		if older.unkeyable() {
+			compatibility = ctx.options.FieldAdditions
+		}
+		for _, name := range sortedKeys(newer.Fields) {
+			sNewer := newer.Fields[name]
+			if _, ok := older.Fields[name]; !ok {
+				ctx.reportAs(at.Child(name, sNewer.Pos).Of("field"), Added, compatibility, nil, sNewer)
 			}
 		}
-		return true
 	} else {
-		return false
+		ctx.report(at, Changed, older, n)
+	}
+}
+
+// unkeyable returns if the struct can be initialized with unkeyed composite
+// literals outside of its package.
+func (s *Struct) unkeyable() bool {
+	for name := range s.Fields {
+		if !token.IsExported(name) {
+			return false
+		}
+	}
+	return len(s.Fields) > 0
+}
+
+func (s *Struct) String() string {
+	fields := []string{}
+	for _, name := range sortedKeys(s.Fields) {
+		fields = append(fields, s.Fields[name].String())
 	}
+	return "struct{" + strings.Join(fields, "; ") + "}"
 }
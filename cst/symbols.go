@@ -0,0 +1,24 @@
+package cst
+
+// Symbols lists every symbol of a project on a separate line, prefixed with
+// its path. Lines are sorted by package and symbol name.
+func Symbols(p *Project) []string {
+	lines := []string{}
+	for _, path := range sortedKeys(p.Packages) {
+		pkg := p.Packages[path]
+		lines = append(lines, path+": "+pkg.String())
+
+		for _, name := range sortedKeys(pkg.Nodes) {
+			node := pkg.Nodes[name]
+			at := Location{Path: path}.Child(name, "")
+			lines = append(lines, at.Path+": "+node.String())
+
+			if typeDef, ok := node.(*TypeDef); ok {
+				for _, method := range sortedKeys(typeDef.Methods) {
+					lines = append(lines, at.Child(method, "").Path+": "+typeDef.Methods[method].String())
+				}
+			}
+		}
+	}
+	return lines
+}
@@ -0,0 +1,74 @@
+package cst
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// DefaultTagKeys lists the struct tag keys compared by default, whose values
+// usually define the wire format of a struct.
+var DefaultTagKeys = []string{"json", "yaml", "xml", "db", "protobuf"}
+
+// tagValue is the value of a single struct tag key.
+type tagValue struct {
+	key, value string
+}
+
+func (t tagValue) Compare(n Node) bool {
+	return t == n
+}
+
+func (t tagValue) String() string {
+	return t.key + ":\"" + t.value + "\""
+}
+
+// diffTags compares the values of the configured tag keys of a field. A
+// missing key is equivalent to the default name of the field without
+// options, as used by encoders.
+func (older *Field) diffTags(ctx *DiffContext, at Location, newer *Field) {
+	for _, key := range ctx.options.TagKeys {
+		oValue, oOk := reflect.StructTag(older.Tag).Lookup(key)
+		nValue, nOk := reflect.StructTag(newer.Tag).Lookup(key)
+		child := at.Child("tag", "").Child(key, "")
+
+		switch {
+		case !oOk && !nOk || oValue == nValue:
+		case !nOk:
+			ctx.reportAs(child, Removed, older.tagCompatibility(key, oValue), tagValue{key, oValue}, nil)
+		case !oOk:
+			ctx.reportAs(child, Added, newer.tagCompatibility(key, nValue), nil, tagValue{key, nValue})
+		default:
+			ctx.report(child, Changed, tagValue{key, oValue}, tagValue{key, nValue})
+		}
+	}
+}
+
+// lowercaseTagKeys lists the tag keys whose encoders default to the
+// lowercased field name.
+var lowercaseTagKeys = map[string]bool{"yaml": true, "db": true}
+
+// tagCompatibility returns the compatibility of adding or removing a tag
+// value, which is compatible only if it is the default name of the field
+// without options.
+func (f *Field) tagCompatibility(key, value string) Compatibility {
+	name := f.Name
+	if lowercaseTagKeys[key] {
+		name = strings.ToLower(name)
+	}
+	if value == name {
+		return Compatible
+	}
+	return Breaking
+}
+
+// tagString renders a field tag for display.
+func tagString(tag string) string {
+	if tag == "" {
+		return ""
+	}
+	if strings.Contains(tag, "`") {
+		return " " + strconv.Quote(tag)
+	}
+	return " `" + tag + "`"
+}
@@ -2,22 +2,85 @@ package cst
 
 // TypeDef represents a type definition node.
 type TypeDef struct {
-	Name string
-	Type Type
+	Name       string
+	TypeParams *TypeParams
+	Type       Type
+	Pos        string
+
+	// Methods declared with the type as reciever, keyed by method name.
+	Methods map[string]*Func
 }
 
 func (older *TypeDef) Compare(n Node) bool {
+	return equivalent(older, n)
+}
+
+func (older *TypeDef) Diff(ctx *DiffContext, at Location, n Node) {
 	if newer, ok := n.(*TypeDef); ok {
 		if older.Name != newer.Name {
-			return false
+			ctx.report(at, Changed, older, newer)
+			return
 		}
 
-		if !older.Type.Compare(newer.Type) {
-			return false
+		newer = newer.renamed(typeParamRenaming(older.TypeParams, newer.TypeParams))
+		diffTypeParams(ctx, at, older.TypeParams, newer.TypeParams)
+
+		if older.Type == nil || newer.Type == nil {
+			if older.Type != newer.Type {
+				ctx.report(at, Changed, older, newer)
+			}
+		} else {
+			diffType(ctx, at, older.Type, newer.Type)
+		}
+
+		var promoted map[string]member
+		if ctx.newer != nil {
+			promoted = ctx.newer.promoted(at.Package, newer)
+		}
+		for _, name := range sortedKeys(older.Methods) {
+			sOlder := older.Methods[name]
+			child := at.Child(name, sOlder.Pos).Of("method")
+			if sNewer, ok := newer.Methods[name]; ok {
+				// A method moving from a value to a pointer reciever is no
+				// longer part of the value's method set. The opposite is safe.
+				if !sOlder.PointerReciever() && sNewer.PointerReciever() {
+					ctx.report(child, Changed, sOlder, sNewer)
+				}
+				sOlder.diffSignature(ctx, child, sNewer, ctx.options.FuncValues)
+			} else if m, ok := promoted[name]; ok {
+				// The method moved onto an embedded type and is still
+				// promoted to the type.
+				if sNewer, ok := m.Node.(*Func); !ok {
+					ctx.report(child, Changed, sOlder, m.Node)
+				} else if !sOlder.PointerReciever() && sNewer.PointerReciever() && !m.Indirect {
+					ctx.report(child, Changed, sOlder, sNewer)
+				} else {
+					sOlder.diffSignature(ctx, child, sNewer, ctx.options.FuncValues)
+				}
+			} else {
+				ctx.report(child, Removed, sOlder, nil)
+			}
 		}
 
-		return true
+		for _, name := range sortedKeys(newer.Methods) {
+			sNewer := newer.Methods[name]
+			if _, ok := older.Methods[name]; !ok {
+				ctx.reportAs(at.Child(name, sNewer.Pos).Of("method"), Added, Compatible, nil, sNewer)
+			}
+		}
+
+		if ctx.older != nil && ctx.newer != nil {
+			older.diffPromoted(ctx, at, newer)
+		}
+		older.diffComparability(ctx, at, newer)
 	} else {
-		return false
+		ctx.report(at, Changed, older, n)
+	}
+}
+
+func (t *TypeDef) String() string {
+	if t.Type == nil {
+		return "type " + t.Name + t.TypeParams.String()
 	}
+	return "type " + t.Name + t.TypeParams.String() + " " + t.Type.String()
 }
@@ -0,0 +1,168 @@
+package cst
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TypeParam represents a type parameter of a generic function or type.
+type TypeParam struct {
+	Name       string
+	Constraint Type
+}
+
+func (tp *TypeParam) String() string {
+	return tp.Name + " " + tp.Constraint.String()
+}
+
+// TypeParams represents the type parameters node of a generic function or type.
+type TypeParams struct {
+	Params []*TypeParam
+}
+
+func (older *TypeParams) Compare(n Node) bool {
+	return equivalent(older, n)
+}
+
+// Diff reports added and removed type parameters, which break explicit
+// instantiations, and changed constraints. Loosening a constraint is
+// compatible, while tightening it breaks existing instantiations.
+func (older *TypeParams) Diff(ctx *DiffContext, at Location, n Node) {
+	if newer, ok := n.(*TypeParams); ok {
+		if len(older.Params) != len(newer.Params) {
+			ctx.report(at, Changed, older, newer)
+			return
+		}
+
+		for i, oParam := range older.Params {
+			nParam := newer.Params[i]
+			child := at.Child(fmt.Sprintf("%d", i), "")
+			switch compareConstraints(ctx, at.Package, oParam.Constraint, nParam.Constraint) {
+			case sameConstraint:
+			case looserConstraint:
+				ctx.reportAs(child, Changed, Compatible, oParam.Constraint, nParam.Constraint)
+			default:
+				ctx.report(child, Changed, oParam.Constraint, nParam.Constraint)
+			}
+		}
+	} else {
+		ctx.report(at, Changed, older, n)
+	}
+}
+
+func (tp *TypeParams) String() string {
+	if tp == nil || len(tp.Params) == 0 {
+		return ""
+	}
+	params := make([]string, len(tp.Params))
+	for i, p := range tp.Params {
+		params[i] = p.String()
+	}
+	return "[" + strings.Join(params, ", ") + "]"
+}
+
+// diffTypeParams compares optional type parameter lists.
+func diffTypeParams(ctx *DiffContext, at Location, older, newer *TypeParams) {
+	if older == nil && newer == nil {
+		return
+	}
+	if older == nil {
+		older = &TypeParams{}
+	}
+	if newer == nil {
+		newer = &TypeParams{}
+	}
+	older.Diff(ctx, at.Child("typeparams", ""), newer)
+}
+
+// constraintRelation describes how the type set of a constraint changed.
+type constraintRelation int
+
+const (
+	sameConstraint constraintRelation = iota
+	looserConstraint
+	tighterConstraint
+)
+
+// typeSet is the normalized form of a constraint.
+type typeSet struct {
+	any        bool
+	comparable bool
+	union      *Union
+	methods    map[string]*Func
+
+	// opaque marks constraints that can only be compared by identity, such
+	// as named constraints declared elsewhere.
+	opaque bool
+}
+
+// newTypeSet normalizes a constraint. The methods of embedded interfaces are
+// flattened using the project, while embedded interfaces which are not
+// declared in it or restrict the type set further are opaque.
+func newTypeSet(p *Project, pkg string, constraint Type) *typeSet {
+	switch c := constraint.(type) {
+	case nil:
+		return &typeSet{any: true}
+	case *SimpleType:
+		switch c.Name {
+		case "any":
+			return &typeSet{any: true}
+		case "comparable":
+			return &typeSet{comparable: true}
+		}
+	case *Union:
+		return &typeSet{union: c}
+	case *Interface:
+		set := p.methodSet(pkg, c)
+		ts := &typeSet{union: c.TypeSet, methods: map[string]*Func{}, opaque: set.typeSets}
+		for name, m := range set.methods {
+			if f, ok := m.Node.(*Func); ok {
+				ts.methods[name] = f
+			}
+		}
+		for _, t := range set.unresolved {
+			switch embedded := newTypeSet(p, pkg, t); {
+			case embedded.any:
+			case embedded.comparable:
+				ts.comparable = true
+			default:
+				ts.opaque = true
+			}
+		}
+		ts.any = len(ts.methods) == 0 && ts.union == nil && !ts.comparable && !ts.opaque
+		return ts
+	}
+	return &typeSet{opaque: true}
+}
+
+// compareConstraints returns if the newer constraint accepts the same, more
+// or not all of the type arguments accepted by the older one.
+func compareConstraints(ctx *DiffContext, pkg string, older, newer Type) constraintRelation {
+	if older == nil && newer == nil || older != nil && newer != nil && older.Compare(newer) {
+		return sameConstraint
+	}
+
+	oSet, nSet := newTypeSet(ctx.older, pkg, older), newTypeSet(ctx.newer, pkg, newer)
+	switch {
+	case oSet.any && nSet.any:
+		return sameConstraint
+	case nSet.any:
+		return looserConstraint
+	case oSet.any, oSet.opaque, nSet.opaque:
+		return tighterConstraint
+	case nSet.comparable && !oSet.comparable:
+		return tighterConstraint
+	}
+
+	// Type arguments implement all methods of the older constraint.
+	for name, nMethod := range nSet.methods {
+		if oMethod, ok := oSet.methods[name]; !ok || !oMethod.Compare(nMethod) {
+			return tighterConstraint
+		}
+	}
+
+	if nSet.union != nil && (oSet.union == nil || !nSet.union.covers(oSet.union)) {
+		return tighterConstraint
+	}
+	return looserConstraint
+}
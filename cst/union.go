@@ -0,0 +1,66 @@
+package cst
+
+import "strings"
+
+// Term represents a single term of a type union - T or ~T.
+type Term struct {
+	Tilde bool
+	Type  Type
+}
+
+// covers returns if every type in the type set of a term is also in the
+// type set of this term.
+func (t *Term) covers(other *Term) bool {
+	if !t.Type.Compare(other.Type) {
+		return false
+	}
+	return t.Tilde || !other.Tilde
+}
+
+func (t *Term) String() string {
+	if t.Tilde {
+		return "~" + t.Type.String()
+	}
+	return t.Type.String()
+}
+
+// Union represents a type union node used in constraints - ~int | ~string, etc...
+type Union struct {
+	Terms []*Term
+}
+
+func (older *Union) Compare(n Node) bool {
+	if newer, ok := n.(*Union); ok {
+		if len(older.Terms) != len(newer.Terms) {
+			return false
+		}
+		return older.covers(newer) && newer.covers(older)
+	} else {
+		return false
+	}
+}
+
+// covers returns if the type set of the union includes all types of another one.
+func (u *Union) covers(other *Union) bool {
+	for _, oTerm := range other.Terms {
+		covered := false
+		for _, term := range u.Terms {
+			if term.covers(oTerm) {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			return false
+		}
+	}
+	return true
+}
+
+func (u *Union) String() string {
+	terms := make([]string, len(u.Terms))
+	for i, term := range u.Terms {
+		terms[i] = term.String()
+	}
+	return strings.Join(terms, " | ")
+}
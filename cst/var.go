@@ -4,20 +4,35 @@ package cst
 type Var struct {
 	Name string
 	Type Type
+	Pos  string
 }
 
 func (older *Var) Compare(n Node) bool {
+	return equivalent(older, n)
+}
+
+func (older *Var) Diff(ctx *DiffContext, at Location, n Node) {
 	if newer, ok := n.(*Var); ok {
 		if older.Name != newer.Name {
-			return false
+			ctx.report(at, Changed, older, newer)
+			return
 		}
 
-		if !older.Type.Compare(newer.Type) {
-			return false
+		// Types which could not be inferred can not be compared.
+		if !isUnknown(older.Type) && !isUnknown(newer.Type) {
+			diffType(ctx, at, older.Type, newer.Type)
 		}
-
-		return true
 	} else {
-		return false
+		ctx.report(at, Changed, older, n)
 	}
 }
+
+func (v *Var) String() string {
+	return "var " + v.Name + " " + v.Type.String()
+}
+
+// isUnknown returns if a type could not be inferred.
+func isUnknown(t Type) bool {
+	st, ok := t.(*SimpleType)
+	return ok && st.Name == UnknownType
+}
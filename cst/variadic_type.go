@@ -0,0 +1,34 @@
+package cst
+
+import "strings"
+
+// VariadicType represents a variadic parameter of a composite type node - ...[]byte, etc...
+// Variadic parameters of named types are kept as simple types for brevity.
+type VariadicType struct {
+	Elem Type
+}
+
+func (older *VariadicType) Compare(n Node) bool {
+	if newer, ok := n.(*VariadicType); ok {
+		return older.Elem.Compare(newer.Elem)
+	} else {
+		return false
+	}
+}
+
+func (t *VariadicType) String() string {
+	return "..." + t.Elem.String()
+}
+
+// variadicElem returns the element type of a variadic parameter.
+func variadicElem(t Type) (Type, bool) {
+	switch t := t.(type) {
+	case *VariadicType:
+		return t.Elem, true
+	case *SimpleType:
+		if strings.HasPrefix(t.Name, "...") {
+			return &SimpleType{strings.TrimPrefix(t.Name, "...")}, true
+		}
+	}
+	return nil, false
+}
@@ -1,8 +1,17 @@
 package main
 
 import (
+	"bytes"
+	"fmt"
 	"go/ast"
+	"go/printer"
 	"go/token"
+	"go/types"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
 	"unicode"
 
 	"github.com/s2gatev/gocompat/cst"
@@ -13,6 +22,204 @@ import (
 type InterfaceContext struct {
 	CurrentPackage *cst.Package
 	Project        *cst.Project
+	FileSet        *token.FileSet
+
+	// ModulePath is the import path of the project root directory. Packages
+	// in nested directories are keyed by their import path relative to it.
+	ModulePath string
+
+	// IncludeInternal adds internal and main packages to the interface even
+	// though they can not be imported by other projects.
+	IncludeInternal bool
+
+	// Imports maps the names under which packages are imported in the
+	// current file to their import paths.
+	Imports map[string]string
+
+	// DotImports lists the import paths of packages imported with a dot.
+	DotImports []string
+
+	// CurrentDecl is the declaration containing the specs being processed.
+	CurrentDecl *ast.GenDecl
+
+	// Constants holds the values of the constants declared so far, keyed by
+	// package path and name, exported or not.
+	Constants map[string]*constValue
+
+	// pendingConsts holds the constants whose values are not known yet.
+	pendingConsts []*pendingConst
+
+	// pendingDotImports holds the names attributed to dot imports, which may
+	// still be declared by a file of the package scanned later.
+	pendingDotImports []*pendingDotImport
+
+	// TypeCheck builds the interface from the objects of the packages type
+	// checked with go/types instead of the syntax of each file. All files
+	// then share the FileSet and are processed by checkTypes once scanned.
+	TypeCheck bool
+
+	// packageFiles holds the files to type check, keyed by import path.
+	packageFiles map[string][]*ast.File
+
+	// dependencies holds the named types referred to by the type checked
+	// packages, whose method sets are recorded if declared outside of the
+	// project.
+	dependencies map[*types.TypeName]bool
+}
+
+// sealedMarker marks interfaces that are not meant to be implemented
+// outside of their package.
+const sealedMarker = "//gocompat:sealed"
+
+// hasMarker returns if any of the comment groups contains the given marker line.
+func hasMarker(marker string, docs ...*ast.CommentGroup) bool {
+	for _, doc := range docs {
+		if doc == nil {
+			continue
+		}
+		for _, c := range doc.List {
+			if strings.TrimSpace(c.Text) == marker {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// declDoc returns the documentation of the current declaration if it
+// consists of a single ungrouped spec.
+func (ic *InterfaceContext) declDoc() *ast.CommentGroup {
+	if ic.CurrentDecl == nil || ic.CurrentDecl.Lparen.IsValid() {
+		return nil
+	}
+	return ic.CurrentDecl.Doc
+}
+
+// typeDef returns the type definition with the given name in the current
+// package, following aliases of local types. Methods may be declared before
+// their type, so a definition without a type is created if the type has not
+// been processed yet.
+func (ic *InterfaceContext) typeDef(name string) *cst.TypeDef {
+	nodes := ic.nodes(name)
+	switch n := nodes[name].(type) {
+	case *cst.TypeDef:
+		return n
+	case *cst.Alias:
+		// Methods declared on an alias belong to the aliased type.
+		if target, ok := n.Type.(*cst.SimpleType); ok && target.Name != name && !strings.HasPrefix(target.Name, "*") {
+			return ic.typeDef(target.Name)
+		}
+	}
+	typeDef := &cst.TypeDef{Name: name, Methods: map[string]*cst.Func{}}
+	nodes[name] = typeDef
+	return typeDef
+}
+
+// nodes returns the nodes of the current package holding a declaration,
+// which are the unexported ones for unexported names.
+func (ic *InterfaceContext) nodes(name string) map[string]cst.Node {
+	if isExported(name) {
+		return ic.CurrentPackage.Nodes
+	}
+	if ic.CurrentPackage.Unexported == nil {
+		ic.CurrentPackage.Unexported = map[string]cst.Node{}
+	}
+	return ic.CurrentPackage.Unexported
+}
+
+// importPath returns the import path of the package a file belongs to,
+// based on the directory of the file relative to the project root.
+func (ic *InterfaceContext) importPath(file *ast.File) string {
+	dir := "."
+	if ic.FileSet != nil {
+		dir = path.Dir(filepath.ToSlash(ic.FileSet.Position(file.Pos()).Filename))
+	}
+
+	if dir == "." {
+		return ic.ModulePath
+	}
+	if ic.ModulePath == "" {
+		return dir
+	}
+	return ic.ModulePath + "/" + dir
+}
+
+// position returns the file:line position of a node in the processed file.
+func (ic *InterfaceContext) position(node ast.Node) string {
+	if ic.FileSet == nil {
+		return ""
+	}
+	p := ic.FileSet.Position(node.Pos())
+	return fmt.Sprintf("%s:%d", p.Filename, p.Line)
+}
+
+// importName guesses the name of a package from its import path, skipping
+// major version suffixes such as "/v2" and "gopkg.in/yaml.v2".
+func importName(path string) string {
+	elements := strings.Split(path, "/")
+	name := elements[len(elements)-1]
+	if versionSuffix.MatchString(name) && len(elements) > 1 {
+		name = elements[len(elements)-2]
+	}
+	if i := strings.Index(name, ".v"); i > 0 {
+		name = name[:i]
+	}
+	return strings.TrimPrefix(name, "go-")
+}
+
+var versionSuffix = regexp.MustCompile(`^v[0-9]+$`)
+
+// qualify resolves the package a type name refers to. Names are qualified only
+// when they come from a single dot import, are not declared in the current file
+// and have not been seen in the current package so far. Names declared by the
+// package later are resolved by resolveDotImports.
+func (ic *InterfaceContext) qualify(ident *ast.Ident) cst.Type {
+	if len(ic.DotImports) == 1 && ident.Obj == nil && isExported(ident.Name) {
+		if _, ok := ic.CurrentPackage.Nodes[ident.Name]; !ok {
+			ic.pendingDotImports = append(ic.pendingDotImports, &pendingDotImport{
+				Package: ic.CurrentPackage,
+				Path:    ic.DotImports[0],
+				Name:    ident.Name,
+			})
+			return &cst.QualifiedType{Path: ic.DotImports[0], Name: ident.Name}
+		}
+	}
+	return &cst.SimpleType{ident.Name}
+}
+
+// pendingDotImport is a name of a package attributed to a dot import.
+type pendingDotImport struct {
+	Package *cst.Package
+	Path    string
+	Name    string
+}
+
+// resolveDotImports refers to the types of the package itself for the names
+// attributed to dot imports which the package turned out to declare, so that
+// the result does not depend on the order in which files are scanned.
+func (ic *InterfaceContext) resolveDotImports() {
+	declared := map[*cst.Package]map[string]map[string]bool{}
+	var remaining []*pendingDotImport
+	for _, pending := range ic.pendingDotImports {
+		if _, ok := pending.Package.Nodes[pending.Name]; !ok {
+			remaining = append(remaining, pending)
+			continue
+		}
+		if declared[pending.Package] == nil {
+			declared[pending.Package] = map[string]map[string]bool{}
+		}
+		if declared[pending.Package][pending.Path] == nil {
+			declared[pending.Package][pending.Path] = map[string]bool{}
+		}
+		declared[pending.Package][pending.Path][pending.Name] = true
+	}
+	ic.pendingDotImports = remaining
+
+	for pkg, paths := range declared {
+		for path, names := range paths {
+			pkg.Unqualify(path, names)
+		}
+	}
 }
 
 // isExporeted returns if a given name should be public or private.
@@ -23,6 +230,20 @@ func isExported(name string) bool {
 	return false
 }
 
+// isExportedPackage returns if a package can be imported by other projects.
+// Packages under an internal directory and main packages can not.
+func isExportedPackage(path, name string) bool {
+	if name == "main" {
+		return false
+	}
+	for _, element := range strings.Split(path, "/") {
+		if element == "internal" {
+			return false
+		}
+	}
+	return true
+}
+
 // kindToType transforms Go token kind to type name.
 func kindToType(kind token.Token) string {
 	switch kind.String() {
@@ -30,146 +251,422 @@ func kindToType(kind token.Token) string {
 		return "string"
 	case "INT":
 		return "int"
+	case "FLOAT":
+		return "float64"
+	case "IMAG":
+		return "complex128"
+	case "CHAR":
+		return "rune"
 	default:
 		return ""
 	}
 }
 
-func extractTypes(node ast.Node) []cst.Type {
+// exprString renders an expression as Go source.
+func exprString(node ast.Node) string {
+	buffer := bytes.Buffer{}
+	printer.Fprint(&buffer, token.NewFileSet(), node)
+	return buffer.String()
+}
+
+func extractTypes(node ast.Node, context *InterfaceContext) []cst.Type {
 	types := []cst.Type{}
 	switch n := node.(type) {
 	case *ast.BasicLit:
 		types = append(types, &cst.SimpleType{kindToType(n.Kind)})
 	case *ast.Ident:
-		types = append(types, &cst.SimpleType{n.Name})
+		types = append(types, context.qualify(n))
+	case *ast.SelectorExpr:
+		if x, ok := n.X.(*ast.Ident); ok {
+			path, ok := context.Imports[x.Name]
+			if !ok {
+				path = x.Name
+			}
+			types = append(types, &cst.QualifiedType{Path: path, Name: n.Sel.Name})
+		}
+	case *ast.ParenExpr:
+		types = extractTypes(n.X, context)
 	case *ast.Ellipsis:
-		types = extractTypes(n.Elt)
-		for _, t := range types {
+		types = extractTypes(n.Elt, context)
+		for i, t := range types {
 			if st, ok := t.(*cst.SimpleType); ok {
 				st.Name = "..." + st.Name
+			} else {
+				types[i] = &cst.VariadicType{Elem: t}
 			}
 		}
 	case *ast.StarExpr:
-		types = extractTypes(n.X)
-		for _, t := range types {
+		types = extractTypes(n.X, context)
+		for i, t := range types {
 			if st, ok := t.(*cst.SimpleType); ok {
 				st.Name = "*" + st.Name
+			} else {
+				types[i] = &cst.PointerType{Elem: t}
 			}
 		}
+	case *ast.ArrayType:
+		elem := extractType(n.Elt, context)
+		if n.Len == nil {
+			types = append(types, &cst.SliceType{Elem: elem})
+		} else {
+			types = append(types, &cst.ArrayType{Len: exprString(n.Len), Elem: elem})
+		}
+	case *ast.MapType:
+		types = append(types, &cst.MapType{
+			Key:   extractType(n.Key, context),
+			Value: extractType(n.Value, context),
+		})
+	case *ast.ChanType:
+		dir := cst.BothDir
+		switch n.Dir {
+		case ast.SEND:
+			dir = cst.SendDir
+		case ast.RECV:
+			dir = cst.RecvDir
+		}
+		types = append(types, &cst.ChanType{Dir: dir, Elem: extractType(n.Value, context)})
+	case *ast.FuncType:
+		params, results := extractFuncTypeDefinition(n, context)
+		types = append(types, &cst.FuncType{Params: params, Results: results})
 	case *ast.StructType:
-		types = append(types, extractStruct(n))
+		types = append(types, extractStruct(n, context))
+	case *ast.InterfaceType:
+		types = append(types, extractInterface(n, context))
+	case *ast.IndexExpr:
+		types = append(types, &cst.Instance{
+			Type: extractType(n.X, context),
+			Args: []cst.Type{extractType(n.Index, context)},
+		})
+	case *ast.IndexListExpr:
+		args := make([]cst.Type, len(n.Indices))
+		for i, index := range n.Indices {
+			args[i] = extractType(index, context)
+		}
+		types = append(types, &cst.Instance{Type: extractType(n.X, context), Args: args})
 	}
 	return types
 }
 
-func extractFields(s *ast.StructType) map[string]*cst.Field {
+// extractType extracts a single type from a type expression. Unsupported
+// expressions are kept as simple types named after their source.
+func extractType(node ast.Node, context *InterfaceContext) cst.Type {
+	if types := extractTypes(node, context); len(types) > 0 {
+		return types[0]
+	}
+	return &cst.SimpleType{exprString(node)}
+}
+
+// extractFieldTypes extracts one type for each name in a field list, or a
+// single type for every unnamed entry.
+func extractFieldTypes(list *ast.FieldList, context *InterfaceContext) []cst.Type {
+	var fieldTypes []cst.Type
+	for _, f := range list.List {
+		for range f.Names {
+			fieldTypes = append(fieldTypes, extractType(f.Type, context))
+		}
+		if f.Names == nil {
+			fieldTypes = append(fieldTypes, extractType(f.Type, context))
+		}
+	}
+	return fieldTypes
+}
+
+func extractFields(s *ast.StructType, context *InterfaceContext) map[string]*cst.Field {
 	fields := map[string]*cst.Field{}
 	for _, f := range s.Fields.List {
+		var tag string
+		if f.Tag != nil {
+			tag, _ = strconv.Unquote(f.Tag.Value)
+		}
 		for _, n := range f.Names {
-			fields[n.Name] = &cst.Field{n.Name, extractTypes(f.Type)[0]}
+			fields[n.Name] = &cst.Field{
+				Name: n.Name,
+				Type: extractType(f.Type, context),
+				Pos:  context.position(n),
+				Tag:  tag,
+			}
+		}
+		if f.Names == nil {
+			name := embeddedFieldName(f.Type)
+			fields[name] = &cst.Field{
+				Name:     name,
+				Type:     extractType(f.Type, context),
+				Pos:      context.position(f),
+				Embedded: true,
+				Tag:      tag,
+			}
 		}
 	}
 	return fields
 }
 
-func extractFuncs(i *ast.InterfaceType) map[string]*cst.Func {
+func extractFuncs(i *ast.InterfaceType, context *InterfaceContext) map[string]*cst.Func {
 	funcs := map[string]*cst.Func{}
 	for _, f := range i.Methods.List {
+		funcType, ok := f.Type.(*ast.FuncType)
+		if !ok {
+			continue
+		}
 		for _, n := range f.Names {
-			params, results := extractFuncTypeDefinition(f.Type.(*ast.FuncType))
-			funcs[n.Name] = &cst.Func{n.Name, nil, params, results}
+			if !isExported(n.Name) {
+				continue
+			}
+			params, results := extractFuncTypeDefinition(funcType, context)
+			funcs[n.Name] = &cst.Func{
+				Name:    n.Name,
+				Params:  params,
+				Results: results,
+				Pos:     context.position(n),
+			}
 		}
 	}
 	return funcs
 }
 
-func extractStruct(s *ast.StructType) *cst.Struct {
-	return &cst.Struct{Fields: extractFields(s)}
+// extractInterface extracts an interface type. Interfaces with unexported
+// methods can not be implemented by other packages, so they are sealed.
+func extractInterface(i *ast.InterfaceType, context *InterfaceContext) *cst.Interface {
+	sealed := false
+	var embeds []cst.Type
+	var typeSet *cst.Union
+	for _, f := range i.Methods.List {
+		for _, n := range f.Names {
+			if !isExported(n.Name) {
+				sealed = true
+			}
+		}
+		if f.Names == nil {
+			if union := extractUnion(f.Type, context); union != nil {
+				typeSet = union
+			} else {
+				embeds = append(embeds, extractType(f.Type, context))
+			}
+		}
+	}
+	return &cst.Interface{
+		Funcs:   extractFuncs(i, context),
+		Embeds:  embeds,
+		TypeSet: typeSet,
+		Sealed:  sealed,
+	}
+}
+
+// extractUnion extracts the terms of a type union - ~int | ~string, etc...
+// Expressions which are not unions or approximation terms yield nil.
+func extractUnion(expr ast.Expr, context *InterfaceContext) *cst.Union {
+	switch e := expr.(type) {
+	case *ast.BinaryExpr:
+		if e.Op != token.OR {
+			return nil
+		}
+		union := &cst.Union{}
+		for _, operand := range []ast.Expr{e.X, e.Y} {
+			if terms := extractUnion(operand, context); terms != nil {
+				union.Terms = append(union.Terms, terms.Terms...)
+			} else {
+				union.Terms = append(union.Terms, &cst.Term{Type: extractType(operand, context)})
+			}
+		}
+		return union
+	case *ast.UnaryExpr:
+		if e.Op != token.TILDE {
+			return nil
+		}
+		return &cst.Union{Terms: []*cst.Term{{Tilde: true, Type: extractType(e.X, context)}}}
+	case *ast.ParenExpr:
+		return extractUnion(e.X, context)
+	default:
+		return nil
+	}
 }
 
-func extractFuncTypeDefinition(f *ast.FuncType) (*cst.Params, *cst.Results) {
+// extractConstraint extracts the constraint of a type parameter.
+func extractConstraint(expr ast.Expr, context *InterfaceContext) cst.Type {
+	if union := extractUnion(expr, context); union != nil {
+		return union
+	}
+	return extractType(expr, context)
+}
+
+// extractTypeParams extracts the type parameters of a generic function or type.
+func extractTypeParams(list *ast.FieldList, context *InterfaceContext) *cst.TypeParams {
+	if list == nil || len(list.List) == 0 {
+		return nil
+	}
+	typeParams := &cst.TypeParams{}
+	for _, f := range list.List {
+		for _, n := range f.Names {
+			typeParams.Params = append(typeParams.Params, &cst.TypeParam{
+				Name:       n.Name,
+				Constraint: extractConstraint(f.Type, context),
+			})
+		}
+	}
+	return typeParams
+}
+
+func extractStruct(s *ast.StructType, context *InterfaceContext) *cst.Struct {
+	return &cst.Struct{Fields: extractFields(s, context)}
+}
+
+func extractFuncTypeDefinition(f *ast.FuncType, context *InterfaceContext) (*cst.Params, *cst.Results) {
 	var params *cst.Params
 	var results *cst.Results
 
 	// Extract params.
 	if f.Params != nil && f.Params.List != nil {
-		var paramTypes []cst.Type
-		for _, p := range f.Params.List {
-			for _, _ = range p.Names {
-				paramTypes = append(paramTypes, extractTypes(p.Type)...)
-			}
-			if p.Names == nil {
-				paramTypes = append(paramTypes, extractTypes(p.Type)...)
-			}
-		}
-		params = &cst.Params{paramTypes}
+		params = &cst.Params{extractFieldTypes(f.Params, context)}
 	}
 
 	// Extract results.
 	if f.Results != nil {
-		var resultTypes []cst.Type
-		for _, r := range f.Results.List {
-			resultTypes = append(resultTypes, extractTypes(r.Type)...)
-		}
-		results = &cst.Results{resultTypes}
+		results = &cst.Results{extractFieldTypes(f.Results, context)}
 	}
 
 	return params, results
 }
 
-func extractFuncDefinition(f *ast.FuncDecl) (*cst.Recievers, *cst.Params, *cst.Results) {
+func extractFuncDefinition(f *ast.FuncDecl, context *InterfaceContext) (*cst.Recievers, *cst.Params, *cst.Results) {
 	var recievers *cst.Recievers
 	var params *cst.Params
 	var results *cst.Results
 
 	// Extract recievers.
 	if f.Recv != nil {
-		var recieverTypes []cst.Type
-		for _, r := range f.Recv.List {
-			for _, _ = range r.Names {
-				recieverTypes = append(recieverTypes, extractTypes(r.Type)...)
-			}
-		}
-		recievers = &cst.Recievers{recieverTypes}
+		recievers = &cst.Recievers{extractFieldTypes(f.Recv, context)}
 	}
 
-	params, results = extractFuncTypeDefinition(f.Type)
+	params, results = extractFuncTypeDefinition(f.Type, context)
 
 	return recievers, params, results
 }
 
+// recieverTypeName returns the name of the type a method is declared on.
+func recieverTypeName(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		return e.Name
+	case *ast.StarExpr:
+		return recieverTypeName(e.X)
+	case *ast.ParenExpr:
+		return recieverTypeName(e.X)
+	case *ast.IndexExpr:
+		return recieverTypeName(e.X)
+	case *ast.IndexListExpr:
+		return recieverTypeName(e.X)
+	default:
+		return ""
+	}
+}
+
+// embeddedFieldName returns the implicit name of an embedded field, which is
+// the name of its type.
+func embeddedFieldName(expr ast.Expr) string {
+	if e, ok := expr.(*ast.SelectorExpr); ok {
+		return e.Sel.Name
+	}
+	if e, ok := expr.(*ast.StarExpr); ok {
+		return embeddedFieldName(e.X)
+	}
+	return recieverTypeName(expr)
+}
+
 func handlePackage(node ast.Node, context interface{}) {
 	if file, ok := node.(*ast.File); ok {
 		context, _ := context.(*InterfaceContext)
 		packageName := file.Name.Name
+		packagePath := context.importPath(file)
+
+		// Symbols of packages which are not part of the interface are still
+		// collected, but into a package that is not added to the project.
+		if !context.IncludeInternal && !isExportedPackage(packagePath, packageName) {
+			context.CurrentPackage = &cst.Package{
+				Name:  packageName,
+				Nodes: map[string]cst.Node{},
+				Path:  packagePath,
+			}
+			return
+		}
+
+		if _, ok := context.Project.Packages[packagePath]; !ok {
+			context.Project.Packages[packagePath] = &cst.Package{
+				Name:  packageName,
+				Nodes: map[string]cst.Node{},
+				Path:  packagePath,
+			}
+		}
+		context.CurrentPackage, _ = context.Project.Packages[packagePath]
+	}
+}
+
+func handleImports(node ast.Node, context interface{}) {
+	if file, ok := node.(*ast.File); ok {
+		context, _ := context.(*InterfaceContext)
+		context.Imports = map[string]string{}
+		context.DotImports = nil
+
+		for _, spec := range file.Imports {
+			path, err := strconv.Unquote(spec.Path.Value)
+			if err != nil {
+				continue
+			}
 
-		if _, ok := context.Project.Packages[packageName]; !ok {
-			context.Project.Packages[packageName] =
-				&cst.Package{packageName, map[string]cst.Node{}}
+			if spec.Name == nil {
+				context.Imports[importName(path)] = path
+			} else if spec.Name.Name == "." {
+				context.DotImports = append(context.DotImports, path)
+			} else if spec.Name.Name != "_" {
+				context.Imports[spec.Name.Name] = path
+			}
 		}
-		context.CurrentPackage, _ = context.Project.Packages[packageName]
 	}
 }
 
 func handleTypeSpec(node ast.Node, context interface{}) {
 	if typeSpec, ok := node.(*ast.TypeSpec); ok {
 		context, _ := context.(*InterfaceContext)
-		current := context.CurrentPackage
 
-		if isExported(typeSpec.Name.Name) {
-			var st cst.Type
-			switch t := typeSpec.Type.(type) {
-			case *ast.StructType:
-				fields := extractFields(t)
-				st = &cst.Struct{fields}
-			case *ast.InterfaceType:
-				funcs := extractFuncs(t)
-				st = &cst.Interface{funcs}
-			default:
-				st = extractTypes(t)[0]
+		// Unexported types are recorded aside from the interface, as
+		// exported members are promoted from them.
+		var st cst.Type
+		switch t := typeSpec.Type.(type) {
+		case *ast.StructType:
+			fields := extractFields(t, context)
+			st = &cst.Struct{fields}
+		case *ast.InterfaceType:
+			i := extractInterface(t, context)
+			if hasMarker(sealedMarker, typeSpec.Doc, context.declDoc()) {
+				i.Sealed = true
 			}
-			current.Nodes[typeSpec.Name.Name] = &cst.TypeDef{typeSpec.Name.Name, st}
+			st = i
+		default:
+			st = extractType(t, context)
 		}
+		if typeSpec.Assign.IsValid() {
+			nodes := context.nodes(typeSpec.Name.Name)
+			placeholder, _ := nodes[typeSpec.Name.Name].(*cst.TypeDef)
+			nodes[typeSpec.Name.Name] = &cst.Alias{
+				Name:       typeSpec.Name.Name,
+				TypeParams: extractTypeParams(typeSpec.TypeParams, context),
+				Type:       st,
+				Pos:        context.position(typeSpec),
+			}
+			// Methods scanned before the alias belong to the aliased type.
+			if placeholder != nil && len(placeholder.Methods) > 0 {
+				if target, ok := st.(*cst.SimpleType); ok && target.Name != typeSpec.Name.Name && !strings.HasPrefix(target.Name, "*") {
+					typeDef := context.typeDef(target.Name)
+					for name, method := range placeholder.Methods {
+						typeDef.Methods[name] = method
+					}
+				}
+			}
+			return
+		}
+
+		typeDef := context.typeDef(typeSpec.Name.Name)
+		typeDef.TypeParams = extractTypeParams(typeSpec.TypeParams, context)
+		typeDef.Type = st
+		typeDef.Pos = context.position(typeSpec)
 	}
 }
 
@@ -179,9 +676,21 @@ func handleFuncDecl(node ast.Node, context interface{}) {
 		current := context.CurrentPackage
 
 		if isExported(funcDecl.Name.Name) {
-			recievers, params, results := extractFuncDefinition(funcDecl)
-			current.Nodes[funcDecl.Name.Name] = &cst.Func{
-				funcDecl.Name.Name, recievers, params, results}
+			recievers, params, results := extractFuncDefinition(funcDecl, context)
+			f := &cst.Func{
+				Name:       funcDecl.Name.Name,
+				TypeParams: extractTypeParams(funcDecl.Type.TypeParams, context),
+				Recievers:  recievers,
+				Params:     params,
+				Results:    results,
+				Pos:        context.position(funcDecl),
+			}
+
+			if funcDecl.Recv == nil {
+				current.Nodes[funcDecl.Name.Name] = f
+			} else if name := recieverTypeName(funcDecl.Recv.List[0].Type); name != "" {
+				context.typeDef(name).Methods[funcDecl.Name.Name] = f
+			}
 		}
 	}
 }
@@ -192,17 +701,41 @@ func handleSpec(spec ast.Node, context interface{}) {
 		current := context.CurrentPackage
 
 		if valueSpec.Type != nil {
-			varTypes := extractTypes(valueSpec.Type)
+			varType := extractType(valueSpec.Type, context)
 			for _, name := range valueSpec.Names {
-				varSpec := &cst.Var{name.Name, varTypes[0]}
+				varSpec := &cst.Var{
+					Name: name.Name,
+					Type: varType,
+					Pos:  context.position(name),
+				}
 				if isExported(name.Name) {
 					current.Nodes[name.Name] = varSpec
 				}
 			}
 		} else {
 			for index, name := range valueSpec.Names {
-				varTypes := extractTypes(valueSpec.Values[index])
-				varSpec := &cst.Var{name.Name, varTypes[0]}
+				// Vars whose type can not be inferred syntactically are
+				// recorded with an unknown type, so that removing them is
+				// still reported.
+				varType := cst.Type(&cst.SimpleType{cst.UnknownType})
+				if index < len(valueSpec.Values) {
+					switch value := valueSpec.Values[index].(type) {
+					case *ast.IndexExpr, *ast.IndexListExpr:
+						// Index expressions in values are element accesses.
+					case *ast.Ident, *ast.SelectorExpr:
+						// Identifiers and selectors in values refer to other
+						// values, e.g. time.Second, rather than to types.
+					default:
+						if varTypes := extractTypes(value, context); len(varTypes) > 0 {
+							varType = varTypes[0]
+						}
+					}
+				}
+				varSpec := &cst.Var{
+					Name: name.Name,
+					Type: varType,
+					Pos:  context.position(name),
+				}
 				if isExported(name.Name) {
 					current.Nodes[name.Name] = varSpec
 				}
@@ -211,12 +744,64 @@ func handleSpec(spec ast.Node, context interface{}) {
 	}
 }
 
+// handleConstSpec records the constants of a spec with the given type and
+// values, which are repeated from the previous spec when omitted. The values
+// are evaluated by resolveConstants.
+func handleConstSpec(valueSpec *ast.ValueSpec, typeExpr ast.Expr, values []ast.Expr, iota int, context *InterfaceContext) {
+	current := context.CurrentPackage
+	if context.Constants == nil {
+		context.Constants = map[string]*constValue{}
+	}
+
+	var declaredType cst.Type
+	if typeExpr != nil {
+		declaredType = extractType(typeExpr, context)
+	}
+
+	file := *context
+	for index, name := range valueSpec.Names {
+		constSpec := &cst.Const{
+			Name: name.Name,
+			Type: declaredType,
+			Pos:  context.position(name),
+		}
+
+		if index < len(values) {
+			context.pendingConsts = append(context.pendingConsts, &pendingConst{
+				Key:  constKey(current.Path, name.Name),
+				Node: constSpec,
+				Expr: values[index],
+				Iota: iota,
+				Type: declaredType,
+				File: &file,
+			})
+		}
+
+		if isExported(name.Name) {
+			current.Nodes[name.Name] = constSpec
+		}
+	}
+}
+
 func handleGenDecl(node ast.Node, context interface{}) {
 	if genDecl, ok := node.(*ast.GenDecl); ok {
 		context, _ := context.(*InterfaceContext)
+		context.CurrentDecl = genDecl
 
-		for _, spec := range genDecl.Specs {
-			handleSpec(spec, context)
+		// Const specs without type and values repeat the previous ones.
+		var typeExpr ast.Expr
+		var values []ast.Expr
+		for iota, spec := range genDecl.Specs {
+			if genDecl.Tok != token.CONST {
+				handleSpec(spec, context)
+				continue
+			}
+			if valueSpec, ok := spec.(*ast.ValueSpec); ok {
+				if valueSpec.Type != nil || valueSpec.Values != nil {
+					typeExpr, values = valueSpec.Type, valueSpec.Values
+				}
+				handleConstSpec(valueSpec, typeExpr, values, iota, context)
+			}
 		}
 	}
 }
@@ -226,11 +811,15 @@ func ProcessFile(
 	file *ast.File,
 	context *InterfaceContext) {
 
+	context.FileSet = fileSet
 	visitor := &ContextPassingVisitor{FileSet: fileSet, AST: file, Context: context}
 	visitor.Handle(handlePackage)
+	visitor.Handle(handleImports)
 	visitor.Handle(handleTypeSpec)
 	visitor.Handle(handleFuncDecl)
 	visitor.Handle(handleGenDecl)
 
 	ast.Walk(visitor, file)
+	context.resolveConstants()
+	context.resolveDotImports()
 }
@@ -1,8 +1,10 @@
 package main
 
 import (
+	"fmt"
 	"go/parser"
 	"go/token"
+	"strings"
 	"testing"
 
 	"github.com/s2gatev/gocompat/cst"
@@ -17,7 +19,8 @@ func testCompat(
 	file, _ := parser.ParseFile(fileSet, "source.go", source, parser.ParseComments)
 
 	actual := &InterfaceContext{
-		Project: &cst.Project{Packages: map[string]*cst.Package{}},
+		Project:    &cst.Project{Packages: map[string]*cst.Package{}},
+		ModulePath: "p",
 	}
 	ProcessFile(fileSet, file, actual)
 
@@ -36,8 +39,8 @@ type MyInt int
 	expected := InterfaceContext{
 		Project: &cst.Project{
 			Packages: map[string]*cst.Package{
-				"p": &cst.Package{"p", map[string]cst.Node{
-					"MyInt": &cst.TypeDef{"MyInt", &cst.SimpleType{"int"}},
+				"p": &cst.Package{Name: "p", Nodes: map[string]cst.Node{
+					"MyInt": &cst.TypeDef{Name: "MyInt", Type: &cst.SimpleType{"int"}},
 				}},
 			},
 		},
@@ -60,11 +63,11 @@ type MyInt struct {
 	expected := InterfaceContext{
 		Project: &cst.Project{
 			Packages: map[string]*cst.Package{
-				"p": &cst.Package{"p", map[string]cst.Node{
-					"MyInt": &cst.TypeDef{"MyInt", &cst.Struct{map[string]*cst.Field{
-						"A": &cst.Field{"A", &cst.SimpleType{"int"}},
-						"B": &cst.Field{"B", &cst.SimpleType{"float32"}},
-						"C": &cst.Field{"C", &cst.SimpleType{"string"}},
+				"p": &cst.Package{Name: "p", Nodes: map[string]cst.Node{
+					"MyInt": &cst.TypeDef{Name: "MyInt", Type: &cst.Struct{map[string]*cst.Field{
+						"A": &cst.Field{Name: "A", Type: &cst.SimpleType{"int"}},
+						"B": &cst.Field{Name: "B", Type: &cst.SimpleType{"float32"}},
+						"C": &cst.Field{Name: "C", Type: &cst.SimpleType{"string"}},
 					}}},
 				}},
 			},
@@ -90,12 +93,12 @@ type MyInt struct {
 	expected := InterfaceContext{
 		Project: &cst.Project{
 			Packages: map[string]*cst.Package{
-				"p": &cst.Package{"p", map[string]cst.Node{
-					"MyInt": &cst.TypeDef{"MyInt", &cst.Struct{map[string]*cst.Field{
-						"A": &cst.Field{"A", &cst.SimpleType{"int"}},
-						"B": &cst.Field{"B", &cst.Struct{map[string]*cst.Field{
-							"C": &cst.Field{"C", &cst.SimpleType{"float32"}},
-							"D": &cst.Field{"D", &cst.SimpleType{"string"}},
+				"p": &cst.Package{Name: "p", Nodes: map[string]cst.Node{
+					"MyInt": &cst.TypeDef{Name: "MyInt", Type: &cst.Struct{map[string]*cst.Field{
+						"A": &cst.Field{Name: "A", Type: &cst.SimpleType{"int"}},
+						"B": &cst.Field{Name: "B", Type: &cst.Struct{map[string]*cst.Field{
+							"C": &cst.Field{Name: "C", Type: &cst.SimpleType{"float32"}},
+							"D": &cst.Field{Name: "D", Type: &cst.SimpleType{"string"}},
 						}}},
 					}}},
 				}},
@@ -116,7 +119,7 @@ type myInt int
 	expected := InterfaceContext{
 		Project: &cst.Project{
 			Packages: map[string]*cst.Package{
-				"p": &cst.Package{"p", map[string]cst.Node{}},
+				"p": &cst.Package{Name: "p", Nodes: map[string]cst.Node{}},
 			},
 		},
 	}
@@ -136,12 +139,11 @@ func NameLength(name string) int {
 	expected := InterfaceContext{
 		Project: &cst.Project{
 			Packages: map[string]*cst.Package{
-				"p": &cst.Package{"p", map[string]cst.Node{
-					"NameLength": &cst.Func{"NameLength",
-						nil,
-						&cst.Params{[]cst.Type{
+				"p": &cst.Package{Name: "p", Nodes: map[string]cst.Node{
+					"NameLength": &cst.Func{Name: "NameLength",
+						Params: &cst.Params{[]cst.Type{
 							&cst.SimpleType{"string"}}},
-						&cst.Results{[]cst.Type{
+						Results: &cst.Results{[]cst.Type{
 							&cst.SimpleType{"int"}}},
 					},
 				}},
@@ -164,14 +166,13 @@ func Something(a, b string, options ...int) (int, bool) {
 	expected := InterfaceContext{
 		Project: &cst.Project{
 			Packages: map[string]*cst.Package{
-				"p": &cst.Package{"p", map[string]cst.Node{
-					"Something": &cst.Func{"Something",
-						nil,
-						&cst.Params{[]cst.Type{
+				"p": &cst.Package{Name: "p", Nodes: map[string]cst.Node{
+					"Something": &cst.Func{Name: "Something",
+						Params: &cst.Params{[]cst.Type{
 							&cst.SimpleType{"string"},
 							&cst.SimpleType{"string"},
 							&cst.SimpleType{"...int"}}},
-						&cst.Results{[]cst.Type{
+						Results: &cst.Results{[]cst.Type{
 							&cst.SimpleType{"int"},
 							&cst.SimpleType{"bool"}}},
 					},
@@ -195,7 +196,7 @@ func something(a, b string, options ...int) (int, bool) {
 	expected := InterfaceContext{
 		Project: &cst.Project{
 			Packages: map[string]*cst.Package{
-				"p": &cst.Package{"p", map[string]cst.Node{}},
+				"p": &cst.Package{Name: "p", Nodes: map[string]cst.Node{}},
 			},
 		},
 	}
@@ -214,15 +215,13 @@ func Something(a, b string, options ...int) {
 	expected := InterfaceContext{
 		Project: &cst.Project{
 			Packages: map[string]*cst.Package{
-				"p": &cst.Package{"p", map[string]cst.Node{
-					"Something": &cst.Func{"Something",
-						nil,
-						&cst.Params{[]cst.Type{
+				"p": &cst.Package{Name: "p", Nodes: map[string]cst.Node{
+					"Something": &cst.Func{Name: "Something",
+						Params: &cst.Params{[]cst.Type{
 							&cst.SimpleType{"string"},
 							&cst.SimpleType{"string"},
 							&cst.SimpleType{"...int"}},
 						},
-						nil,
 					},
 				}},
 			},
@@ -244,14 +243,11 @@ func Something() int {
 	expected := InterfaceContext{
 		Project: &cst.Project{
 			Packages: map[string]*cst.Package{
-				"p": &cst.Package{"p", map[string]cst.Node{
-					"Something": &cst.Func{"Something",
-						nil,
-						nil,
-						&cst.Results{[]cst.Type{
+				"p": &cst.Package{Name: "p", Nodes: map[string]cst.Node{
+					"Something": &cst.Func{Name: "Something",
+						Results: &cst.Results{[]cst.Type{
 							&cst.SimpleType{"int"}}},
-					}},
-				},
+					}}},
 			},
 		},
 	}
@@ -269,8 +265,8 @@ var A int = 5
 	expected := InterfaceContext{
 		Project: &cst.Project{
 			Packages: map[string]*cst.Package{
-				"p": &cst.Package{"p", map[string]cst.Node{
-					"A": &cst.Var{"A", &cst.SimpleType{"int"}},
+				"p": &cst.Package{Name: "p", Nodes: map[string]cst.Node{
+					"A": &cst.Var{Name: "A", Type: &cst.SimpleType{"int"}},
 				}},
 			},
 		},
@@ -289,7 +285,7 @@ var a int = 5
 	expected := InterfaceContext{
 		Project: &cst.Project{
 			Packages: map[string]*cst.Package{
-				"p": &cst.Package{"p", map[string]cst.Node{}},
+				"p": &cst.Package{Name: "p", Nodes: map[string]cst.Node{}},
 			},
 		},
 	}
@@ -309,13 +305,13 @@ var F, G = "answer", 42
 	expected := InterfaceContext{
 		Project: &cst.Project{
 			Packages: map[string]*cst.Package{
-				"p": &cst.Package{"p", map[string]cst.Node{
-					"A": &cst.Var{"A", &cst.SimpleType{"int"}},
-					"B": &cst.Var{"B", &cst.SimpleType{"int"}},
-					"D": &cst.Var{"D", &cst.SimpleType{"int"}},
-					"S": &cst.Var{"S", &cst.SimpleType{"string"}},
-					"F": &cst.Var{"F", &cst.SimpleType{"string"}},
-					"G": &cst.Var{"G", &cst.SimpleType{"int"}},
+				"p": &cst.Package{Name: "p", Nodes: map[string]cst.Node{
+					"A": &cst.Var{Name: "A", Type: &cst.SimpleType{"int"}},
+					"B": &cst.Var{Name: "B", Type: &cst.SimpleType{"int"}},
+					"D": &cst.Var{Name: "D", Type: &cst.SimpleType{"int"}},
+					"S": &cst.Var{Name: "S", Type: &cst.SimpleType{"string"}},
+					"F": &cst.Var{Name: "F", Type: &cst.SimpleType{"string"}},
+					"G": &cst.Var{Name: "G", Type: &cst.SimpleType{"int"}},
 				}},
 			},
 		},
@@ -324,6 +320,45 @@ var F, G = "answer", 42
 	testCompat(t, source, expected)
 }
 
+func TestReferenceVar(t *testing.T) {
+	older := `
+package p
+
+import "time"
+
+var Timeout = time.Second
+var Enabled = true
+var Default = Timeout
+var Now = time.Now()
+var Retries = 3
+var Ratio = 1.5
+`
+
+	newer := `
+package p
+
+import "time"
+
+var Default = 5 * time.Second
+var Now = time.Time{}
+var Retries = 3
+var Ratio = "1.5"
+`
+
+	changes := []string{}
+	for _, change := range cst.BreakingChanges(cst.Diff(parse(older), parse(newer))) {
+		changes = append(changes, change.String())
+	}
+	expected := []string{
+		"source.go:7: p.Enabled: removed var Enabled unknown type",
+		"source.go:11: p.Ratio: changed from float64 to string",
+		"source.go:6: p.Timeout: removed var Timeout unknown type",
+	}
+	if strings.Join(changes, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Expected changes %q, got %q.", expected, changes)
+	}
+}
+
 func TestExportedConst(t *testing.T) {
 	source := `
 package p
@@ -334,8 +369,8 @@ const A int = 5
 	expected := InterfaceContext{
 		Project: &cst.Project{
 			Packages: map[string]*cst.Package{
-				"p": &cst.Package{"p", map[string]cst.Node{
-					"A": &cst.Var{"A", &cst.SimpleType{"int"}},
+				"p": &cst.Package{Name: "p", Nodes: map[string]cst.Node{
+					"A": &cst.Const{Name: "A", Type: &cst.SimpleType{"int"}, Value: "5"},
 				}},
 			},
 		},
@@ -354,7 +389,7 @@ const a int = 5
 	expected := InterfaceContext{
 		Project: &cst.Project{
 			Packages: map[string]*cst.Package{
-				"p": &cst.Package{"p", map[string]cst.Node{}},
+				"p": &cst.Package{Name: "p", Nodes: map[string]cst.Node{}},
 			},
 		},
 	}
@@ -374,13 +409,13 @@ const F, G = "answer", 42
 	expected := InterfaceContext{
 		Project: &cst.Project{
 			Packages: map[string]*cst.Package{
-				"p": &cst.Package{"p", map[string]cst.Node{
-					"A": &cst.Var{"A", &cst.SimpleType{"int"}},
-					"B": &cst.Var{"B", &cst.SimpleType{"int"}},
-					"D": &cst.Var{"D", &cst.SimpleType{"int"}},
-					"S": &cst.Var{"S", &cst.SimpleType{"string"}},
-					"F": &cst.Var{"F", &cst.SimpleType{"string"}},
-					"G": &cst.Var{"G", &cst.SimpleType{"int"}},
+				"p": &cst.Package{Name: "p", Nodes: map[string]cst.Node{
+					"A": &cst.Const{Name: "A", Type: &cst.SimpleType{"int"}, Value: "5"},
+					"B": &cst.Const{Name: "B", Type: &cst.SimpleType{"int"}},
+					"D": &cst.Const{Name: "D", Type: &cst.SimpleType{"int"}},
+					"S": &cst.Const{Name: "S", Type: &cst.SimpleType{"string"}, Value: `"something"`},
+					"F": &cst.Const{Name: "F", Type: &cst.SimpleType{"untyped string"}, Value: `"answer"`},
+					"G": &cst.Const{Name: "G", Type: &cst.SimpleType{"untyped int"}, Value: "42"},
 				}},
 			},
 		},
@@ -402,15 +437,16 @@ func (ms MyStr) Something(a int) {
 	expected := InterfaceContext{
 		Project: &cst.Project{
 			Packages: map[string]*cst.Package{
-				"p": &cst.Package{"p", map[string]cst.Node{
-					"MyStr": &cst.TypeDef{"MyStr", &cst.Struct{map[string]*cst.Field{}}},
-					"Something": &cst.Func{"Something",
-						&cst.Recievers{[]cst.Type{
-							&cst.SimpleType{"MyStr"}}},
-						&cst.Params{[]cst.Type{
-							&cst.SimpleType{"int"}}},
-						nil,
-					},
+				"p": &cst.Package{Name: "p", Nodes: map[string]cst.Node{
+					"MyStr": &cst.TypeDef{Name: "MyStr", Type: &cst.Struct{map[string]*cst.Field{}},
+						Methods: map[string]*cst.Func{
+							"Something": &cst.Func{Name: "Something",
+								Recievers: &cst.Recievers{[]cst.Type{
+									&cst.SimpleType{"MyStr"}}},
+								Params: &cst.Params{[]cst.Type{
+									&cst.SimpleType{"int"}}},
+							},
+						}},
 				}},
 			},
 		},
@@ -432,15 +468,16 @@ func (ms *MyStr) Something(a int) {
 	expected := InterfaceContext{
 		Project: &cst.Project{
 			Packages: map[string]*cst.Package{
-				"p": &cst.Package{"p", map[string]cst.Node{
-					"MyStr": &cst.TypeDef{"MyStr", &cst.Struct{map[string]*cst.Field{}}},
-					"Something": &cst.Func{"Something",
-						&cst.Recievers{[]cst.Type{
-							&cst.SimpleType{"*MyStr"}}},
-						&cst.Params{[]cst.Type{
-							&cst.SimpleType{"int"}}},
-						nil,
-					},
+				"p": &cst.Package{Name: "p", Nodes: map[string]cst.Node{
+					"MyStr": &cst.TypeDef{Name: "MyStr", Type: &cst.Struct{map[string]*cst.Field{}},
+						Methods: map[string]*cst.Func{
+							"Something": &cst.Func{Name: "Something",
+								Recievers: &cst.Recievers{[]cst.Type{
+									&cst.SimpleType{"*MyStr"}}},
+								Params: &cst.Params{[]cst.Type{
+									&cst.SimpleType{"int"}}},
+							},
+						}},
 				}},
 			},
 		},
@@ -462,21 +499,327 @@ type InterStringer interface {
 	expected := InterfaceContext{
 		Project: &cst.Project{
 			Packages: map[string]*cst.Package{
-				"p": &cst.Package{"p", map[string]cst.Node{
-					"InterStringer": &cst.TypeDef{"InterStringer", &cst.Interface{map[string]*cst.Func{
-						"String": &cst.Func{"String",
-							nil,
-							nil,
-							&cst.Results{[]cst.Type{
+				"p": &cst.Package{Name: "p", Nodes: map[string]cst.Node{
+					"InterStringer": &cst.TypeDef{Name: "InterStringer", Type: &cst.Interface{Funcs: map[string]*cst.Func{
+						"String": &cst.Func{Name: "String",
+							Results: &cst.Results{[]cst.Type{
 								&cst.SimpleType{"string"}}},
 						},
-						"Int": &cst.Func{"Int",
-							nil,
-							&cst.Params{[]cst.Type{
+						"Int": &cst.Func{Name: "Int",
+							Params: &cst.Params{[]cst.Type{
 								&cst.SimpleType{"float64"}}},
-							&cst.Results{[]cst.Type{
+							Results: &cst.Results{[]cst.Type{
+								&cst.SimpleType{"int"}}},
+						},
+					}}},
+				}},
+			},
+		},
+	}
+
+	testCompat(t, source, expected)
+}
+
+func TestCompositeTypes(t *testing.T) {
+	source := `
+package p
+
+type A struct {
+	B	map[string]int
+	C	[]byte
+	D	[4]int
+	E	chan<- string
+	F	<-chan string
+	G	func(int) error
+	H	*[]byte
+}
+`
+
+	expected := InterfaceContext{
+		Project: &cst.Project{
+			Packages: map[string]*cst.Package{
+				"p": &cst.Package{Name: "p", Nodes: map[string]cst.Node{
+					"A": &cst.TypeDef{Name: "A", Type: &cst.Struct{map[string]*cst.Field{
+						"B": &cst.Field{Name: "B", Type: &cst.MapType{
+							Key:   &cst.SimpleType{"string"},
+							Value: &cst.SimpleType{"int"}}},
+						"C": &cst.Field{Name: "C", Type: &cst.SliceType{
+							Elem: &cst.SimpleType{"byte"}}},
+						"D": &cst.Field{Name: "D", Type: &cst.ArrayType{
+							Len:  "4",
+							Elem: &cst.SimpleType{"int"}}},
+						"E": &cst.Field{Name: "E", Type: &cst.ChanType{
+							Dir:  cst.SendDir,
+							Elem: &cst.SimpleType{"string"}}},
+						"F": &cst.Field{Name: "F", Type: &cst.ChanType{
+							Dir:  cst.RecvDir,
+							Elem: &cst.SimpleType{"string"}}},
+						"G": &cst.Field{Name: "G", Type: &cst.FuncType{
+							Params: &cst.Params{[]cst.Type{
 								&cst.SimpleType{"int"}}},
+							Results: &cst.Results{[]cst.Type{
+								&cst.SimpleType{"error"}}}}},
+						"H": &cst.Field{Name: "H", Type: &cst.PointerType{
+							Elem: &cst.SliceType{Elem: &cst.SimpleType{"byte"}}}},
+					}}},
+				}},
+			},
+		},
+	}
+
+	testCompat(t, source, expected)
+}
+
+func TestQualifiedTypes(t *testing.T) {
+	source := `
+package p
+
+import (
+	"io"
+	nethttp "net/http"
+	. "time"
+	"gopkg.in/yaml.v2"
+)
+
+func Handle(r io.Reader, h nethttp.Handler, d Duration, n yaml.Node) {
+}
+`
+
+	expected := InterfaceContext{
+		Project: &cst.Project{
+			Packages: map[string]*cst.Package{
+				"p": &cst.Package{Name: "p", Nodes: map[string]cst.Node{
+					"Handle": &cst.Func{Name: "Handle",
+						Params: &cst.Params{[]cst.Type{
+							&cst.QualifiedType{Path: "io", Name: "Reader"},
+							&cst.QualifiedType{Path: "net/http", Name: "Handler"},
+							&cst.QualifiedType{Path: "time", Name: "Duration"},
+							&cst.QualifiedType{Path: "gopkg.in/yaml.v2", Name: "Node"}}},
+					},
+				}},
+			},
+		},
+	}
+
+	testCompat(t, source, expected)
+}
+
+func TestDotImportedTypesDeclaredLater(t *testing.T) {
+	sources := [][2]string{
+		{"a.go", "package p\n\nimport . \"time\"\n\nfunc Wait(d Duration, t Timer) {\n}\n"},
+		{"b.go", "package p\n\ntype Timer struct{}\n"},
+	}
+
+	context := &InterfaceContext{
+		Project:    &cst.Project{Packages: map[string]*cst.Package{}},
+		ModulePath: "p",
+	}
+	for _, source := range sources {
+		fileSet := token.NewFileSet()
+		file, _ := parser.ParseFile(fileSet, source[0], source[1], 0)
+		ProcessFile(fileSet, file, context)
+	}
+
+	expected := "func Wait(time.Duration, Timer)"
+	if wait := context.Project.Packages["p"].Nodes["Wait"]; wait.String() != expected {
+		t.Errorf("Expected %q, got %q.", expected, wait)
+	}
+}
+
+func TestMethodsWithSameName(t *testing.T) {
+	source := `
+package p
+
+func (a *A) Close() error {
+	return nil
+}
+
+type A struct {}
+
+type B struct {}
+
+func (B) Close() {
+}
+`
+
+	expected := InterfaceContext{
+		Project: &cst.Project{
+			Packages: map[string]*cst.Package{
+				"p": &cst.Package{Name: "p", Nodes: map[string]cst.Node{
+					"A": &cst.TypeDef{Name: "A", Type: &cst.Struct{map[string]*cst.Field{}},
+						Methods: map[string]*cst.Func{
+							"Close": &cst.Func{Name: "Close",
+								Recievers: &cst.Recievers{[]cst.Type{
+									&cst.SimpleType{"*A"}}},
+								Results: &cst.Results{[]cst.Type{
+									&cst.SimpleType{"error"}}},
+							},
+						}},
+					"B": &cst.TypeDef{Name: "B", Type: &cst.Struct{map[string]*cst.Field{}},
+						Methods: map[string]*cst.Func{
+							"Close": &cst.Func{Name: "Close",
+								Recievers: &cst.Recievers{[]cst.Type{
+									&cst.SimpleType{"B"}}},
+							},
+						}},
+				}},
+			},
+		},
+	}
+
+	testCompat(t, source, expected)
+}
+
+func TestNestedPackagesWithSameName(t *testing.T) {
+	context := &InterfaceContext{
+		Project:    &cst.Project{Packages: map[string]*cst.Package{}},
+		ModulePath: "example.com/m",
+	}
+
+	for _, filename := range []string{"a/util/util.go", "b/util/util.go"} {
+		fileSet := token.NewFileSet()
+		file, _ := parser.ParseFile(fileSet, filename, "package util\n\nvar A int\n", 0)
+		ProcessFile(fileSet, file, context)
+	}
+
+	for _, path := range []string{"example.com/m/a/util", "example.com/m/b/util"} {
+		if p, ok := context.Project.Packages[path]; !ok {
+			t.Errorf("Expected package %s.", path)
+		} else if p.Name != "util" || p.Path != path {
+			t.Errorf("Unexpected package %s for %s.", p, path)
+		}
+	}
+}
+
+func TestInternalAndMainPackages(t *testing.T) {
+	sources := map[string]string{
+		"a/internal/b/b.go": "package b\n\nvar A int\n",
+		"internal/c.go":     "package internal\n\nvar A int\n",
+		"cmd/tool/main.go":  "package main\n\nvar A int\n",
+		"api/api.go":        "package api\n\nvar A int\n",
+	}
+
+	for _, includeInternal := range []bool{false, true} {
+		context := &InterfaceContext{
+			Project:         &cst.Project{Packages: map[string]*cst.Package{}},
+			ModulePath:      "example.com/m",
+			IncludeInternal: includeInternal,
+		}
+
+		for filename, source := range sources {
+			fileSet := token.NewFileSet()
+			file, _ := parser.ParseFile(fileSet, filename, source, 0)
+			ProcessFile(fileSet, file, context)
+		}
+
+		expected := 1
+		if includeInternal {
+			expected = len(sources)
+		}
+		if len(context.Project.Packages) != expected {
+			t.Errorf("Expected %d packages, got %d.", expected, len(context.Project.Packages))
+		}
+		if _, ok := context.Project.Packages["example.com/m/api"]; !ok {
+			t.Error("Expected package example.com/m/api.")
+		}
+	}
+}
+
+func TestGenericFuncDeclaration(t *testing.T) {
+	source := `
+package p
+
+func Keys[K ~string | int, V any](m map[K]V) []K {
+	return nil
+}
+`
+
+	expected := InterfaceContext{
+		Project: &cst.Project{
+			Packages: map[string]*cst.Package{
+				"p": &cst.Package{Name: "p", Nodes: map[string]cst.Node{
+					"Keys": &cst.Func{
+						Name: "Keys",
+						TypeParams: &cst.TypeParams{Params: []*cst.TypeParam{
+							{Name: "K", Constraint: &cst.Union{Terms: []*cst.Term{
+								{Tilde: true, Type: &cst.SimpleType{"string"}},
+								{Type: &cst.SimpleType{"int"}},
+							}}},
+							{Name: "V", Constraint: &cst.SimpleType{"any"}},
+						}},
+						Params: &cst.Params{[]cst.Type{
+							&cst.MapType{Key: &cst.SimpleType{"K"}, Value: &cst.SimpleType{"V"}},
+						}},
+						Results: &cst.Results{[]cst.Type{
+							&cst.SliceType{Elem: &cst.SimpleType{"K"}},
+						}},
+					},
+				}},
+			},
+		},
+	}
+
+	testCompat(t, source, expected)
+}
+
+func TestGenericTypeDeclaration(t *testing.T) {
+	source := `
+package p
+
+type Set[T comparable] map[T]struct{}
+
+func (s Set[T]) Add(v T) {}
+`
+
+	expected := InterfaceContext{
+		Project: &cst.Project{
+			Packages: map[string]*cst.Package{
+				"p": &cst.Package{Name: "p", Nodes: map[string]cst.Node{
+					"Set": &cst.TypeDef{
+						Name: "Set",
+						TypeParams: &cst.TypeParams{Params: []*cst.TypeParam{
+							{Name: "T", Constraint: &cst.SimpleType{"comparable"}},
+						}},
+						Type: &cst.MapType{Key: &cst.SimpleType{"T"}, Value: &cst.Struct{Fields: map[string]*cst.Field{}}},
+						Methods: map[string]*cst.Func{
+							"Add": &cst.Func{
+								Name: "Add",
+								Recievers: &cst.Recievers{[]cst.Type{
+									&cst.Instance{Type: &cst.SimpleType{"Set"}, Args: []cst.Type{&cst.SimpleType{"T"}}},
+								}},
+								Params: &cst.Params{[]cst.Type{&cst.SimpleType{"T"}}},
+							},
 						},
+					},
+				}},
+			},
+		},
+	}
+
+	testCompat(t, source, expected)
+}
+
+func TestEmbeddedStructFields(t *testing.T) {
+	source := `
+package p
+
+import "io"
+
+type A struct {
+	io.Reader
+	*Base
+	Name	string
+}
+`
+
+	expected := InterfaceContext{
+		Project: &cst.Project{
+			Packages: map[string]*cst.Package{
+				"p": &cst.Package{Name: "p", Nodes: map[string]cst.Node{
+					"A": &cst.TypeDef{Name: "A", Type: &cst.Struct{Fields: map[string]*cst.Field{
+						"Reader": &cst.Field{Name: "Reader", Type: &cst.QualifiedType{Path: "io", Name: "Reader"}, Embedded: true},
+						"Base":   &cst.Field{Name: "Base", Type: &cst.SimpleType{"*Base"}, Embedded: true},
+						"Name":   &cst.Field{Name: "Name", Type: &cst.SimpleType{"string"}},
 					}}},
 				}},
 			},
@@ -485,3 +828,125 @@ type InterStringer interface {
 
 	testCompat(t, source, expected)
 }
+
+func TestIotaConstBlock(t *testing.T) {
+	source := `
+package p
+
+type Status int
+
+const (
+	StatusOK Status = iota
+	StatusFailed
+	_
+	StatusUnknown
+)
+
+const (
+	KB = 1 << (10 * (iota + 1))
+	MB
+	Name = "gocompat"
+	Size = len(Name) * MB / 2
+	Ratio = float64(1) / 4
+)
+`
+
+	expected := InterfaceContext{
+		Project: &cst.Project{
+			Packages: map[string]*cst.Package{
+				"p": &cst.Package{Name: "p", Nodes: map[string]cst.Node{
+					"Status":        &cst.TypeDef{Name: "Status", Type: &cst.SimpleType{"int"}},
+					"StatusOK":      &cst.Const{Name: "StatusOK", Type: &cst.SimpleType{"Status"}, Value: "0"},
+					"StatusFailed":  &cst.Const{Name: "StatusFailed", Type: &cst.SimpleType{"Status"}, Value: "1"},
+					"StatusUnknown": &cst.Const{Name: "StatusUnknown", Type: &cst.SimpleType{"Status"}, Value: "3"},
+					"KB":            &cst.Const{Name: "KB", Type: &cst.SimpleType{"untyped int"}, Value: "1024"},
+					"MB":            &cst.Const{Name: "MB", Type: &cst.SimpleType{"untyped int"}, Value: "1048576"},
+					"Name":          &cst.Const{Name: "Name", Type: &cst.SimpleType{"untyped string"}, Value: `"gocompat"`},
+					"Size":          &cst.Const{Name: "Size", Type: &cst.SimpleType{"int"}, Value: "4194304"},
+					"Ratio":         &cst.Const{Name: "Ratio", Type: &cst.SimpleType{"float64"}, Value: "0.25"},
+				}},
+			},
+		},
+	}
+
+	testCompat(t, source, expected)
+}
+
+func TestConstDeclarationOrder(t *testing.T) {
+	sources := []string{`
+package p
+
+type Level int
+
+const Base = 1
+
+const MaxBuf = MaxSize * 2
+
+const Debug Level = Base
+
+const C = Base + 1
+
+func f() {
+	const MaxSize = 1
+}
+`, `
+package p
+
+const MaxSize = 512
+`}
+
+	fileSet := token.NewFileSet()
+	actual := &InterfaceContext{
+		Project:    &cst.Project{Packages: map[string]*cst.Package{}},
+		ModulePath: "p",
+	}
+	for i, source := range sources {
+		file, _ := parser.ParseFile(fileSet, fmt.Sprintf("source%d.go", i), source, parser.ParseComments)
+		ProcessFile(fileSet, file, actual)
+	}
+
+	expected := map[string]string{
+		"MaxBuf":  "const MaxBuf untyped int = 1024",
+		"Debug":   "const Debug Level = 1",
+		"C":       "const C untyped int = 2",
+		"MaxSize": "const MaxSize untyped int = 512",
+		"Base":    "const Base untyped int = 1",
+	}
+	nodes := actual.Project.Packages["p"].Nodes
+	for name, description := range expected {
+		if node, ok := nodes[name]; !ok || node.String() != description {
+			t.Errorf("Expected %q, got %v.", description, node)
+		}
+	}
+}
+
+func TestAliasDeclaration(t *testing.T) {
+	source := `
+package p
+
+import "io"
+
+type Reader = io.Reader
+
+type Pair[T any] = map[T]T
+`
+
+	expected := InterfaceContext{
+		Project: &cst.Project{
+			Packages: map[string]*cst.Package{
+				"p": &cst.Package{Name: "p", Nodes: map[string]cst.Node{
+					"Reader": &cst.Alias{Name: "Reader", Type: &cst.QualifiedType{Path: "io", Name: "Reader"}},
+					"Pair": &cst.Alias{
+						Name: "Pair",
+						TypeParams: &cst.TypeParams{Params: []*cst.TypeParam{
+							{Name: "T", Constraint: &cst.SimpleType{"any"}},
+						}},
+						Type: &cst.MapType{Key: &cst.SimpleType{"T"}, Value: &cst.SimpleType{"T"}},
+					},
+				}},
+			},
+		},
+	}
+
+	testCompat(t, source, expected)
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"fmt"
+	"go/build"
+	"io"
+	"io/ioutil"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// git runs a git command in a directory, returning its standard output.
+func git(dir string, args ...string) ([]byte, error) {
+	stderr := bytes.Buffer{}
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git %s: %v: %s", strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return output, nil
+}
+
+// readRevision returns the content of all files in a directory at the given
+// git revision, keyed by their slash-separated path relative to the directory.
+func readRevision(dir, revision string) (map[string][]byte, error) {
+	prefix, err := git(dir, "rev-parse", "--show-prefix")
+	if err != nil {
+		return nil, err
+	}
+
+	tree := revision + ":" + strings.TrimSpace(string(prefix))
+	archive, err := git(dir, "archive", "--format=tar", tree)
+	if err != nil {
+		return nil, err
+	}
+
+	files := map[string][]byte{}
+	reader := tar.NewReader(bytes.NewReader(archive))
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		content, err := ioutil.ReadAll(reader)
+		if err != nil {
+			return nil, err
+		}
+		files[path.Clean(header.Name)] = content
+	}
+	return files, nil
+}
+
+// scanRevision adds the symbols of all project files in a directory at the
+// given git revision to the context. The module path is read from the go.mod
+// file of the revision when it has one.
+func scanRevision(dir, revision string, filter *FileFilter, context *InterfaceContext) error {
+	files, err := readRevision(dir, revision)
+	if err != nil {
+		return err
+	}
+
+	if content, ok := files[moduleFileName]; ok {
+		if modulePath := parseModulePath(bytes.NewReader(content)); modulePath != "" {
+			context.ModulePath = modulePath
+		}
+	}
+
+	// Evaluate build constraints against the content of the revision.
+	buildContext := build.Default
+	buildContext.OpenFile = func(name string) (io.ReadCloser, error) {
+		content, ok := files[filepath.ToSlash(filepath.Clean(name))]
+		if !ok {
+			return nil, fmt.Errorf("%s: not found in revision %s", name, revision)
+		}
+		return ioutil.NopCloser(bytes.NewReader(content)), nil
+	}
+	revisionFilter := *filter
+	revisionFilter.Root = ""
+	revisionFilter.Build = &buildContext
+
+	names := []string{}
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if skipsAnyDir(&revisionFilter, name) || !revisionFilter.Match(name) {
+			continue
+		}
+		processSource(name, files[name], context)
+	}
+	if context.TypeCheck {
+		context.checkTypes()
+	}
+	return nil
+}
+
+// skipsAnyDir returns if the filter skips any of the directories a file is in.
+func skipsAnyDir(filter *FileFilter, name string) bool {
+	for dir := path.Dir(name); dir != "."; dir = path.Dir(dir) {
+		if filter.SkipDir(dir) {
+			return true
+		}
+	}
+	return false
+}
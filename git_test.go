@@ -0,0 +1,59 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/s2gatev/gocompat/cst"
+)
+
+func TestScanRevision(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not available")
+	}
+
+	dir, _ := ioutil.TempDir("", "gocompat")
+	defer os.RemoveAll(dir)
+
+	write := func(name, content string) {
+		path := filepath.Join(dir, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		ioutil.WriteFile(path, []byte(content), 0644)
+	}
+	run := func(args ...string) {
+		if _, err := git(dir, args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write("go.mod", "module example.com/m\n")
+	write("a/a.go", "package a\n\nfunc A(b int) {\n}\n")
+	write("a/a_test.go", "package a\n\nfunc B() {\n}\n")
+	run("init", "-q")
+	run("add", ".")
+	run("-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "v1")
+	run("tag", "v1.0.0")
+	write("a/a.go", "package a\n\nfunc A(b string) {\n}\n")
+
+	older := &InterfaceContext{Project: &cst.Project{Packages: map[string]*cst.Package{}}}
+	if err := scanRevision(dir, "v1.0.0", &FileFilter{}, older); err != nil {
+		t.Fatal(err)
+	}
+	newer := &InterfaceContext{
+		Project:    &cst.Project{Packages: map[string]*cst.Package{}},
+		ModulePath: readModulePath(dir),
+	}
+	if err := scanDir(dir, &FileFilter{Root: dir}, newer); err != nil {
+		t.Fatal(err)
+	}
+
+	changes := cst.Diff(older.Project, newer.Project)
+
+	expected := "a/a.go:3: example.com/m/a.A.params.0: changed from int to string"
+	if len(changes) != 1 || changes[0].String() != expected {
+		t.Errorf("Expected change %q, got %v.", expected, changes)
+	}
+}
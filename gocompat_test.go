@@ -3,6 +3,7 @@ package main
 import (
 	"go/parser"
 	"go/token"
+	"strings"
 	"testing"
 
 	"github.com/s2gatev/gocompat/cst"
@@ -13,13 +14,29 @@ func parse(source string) *cst.Project {
 	file, _ := parser.ParseFile(fileSet, "source.go", source, parser.ParseComments)
 
 	context := &InterfaceContext{
-		Project: &cst.Project{Packages: map[string]*cst.Package{}},
+		Project:    &cst.Project{Packages: map[string]*cst.Package{}},
+		ModulePath: "p",
 	}
 	ProcessFile(fileSet, file, context)
 
 	return context.Project
 }
 
+// parseFiles parses the sources of a project keyed by file name.
+func parseFiles(sources map[string]string) *cst.Project {
+	fileSet := token.NewFileSet()
+	context := &InterfaceContext{
+		Project:    &cst.Project{Packages: map[string]*cst.Package{}},
+		ModulePath: "p",
+	}
+	for name, source := range sources {
+		file, _ := parser.ParseFile(fileSet, name, source, parser.ParseComments)
+		ProcessFile(fileSet, file, context)
+	}
+
+	return context.Project
+}
+
 func testCompare(
 	t *testing.T,
 	older, newer string,
@@ -241,3 +258,1863 @@ type A struct {
 
 	testCompare(t, older, newer, false)
 }
+
+func TestDiffReportsAllChanges(t *testing.T) {
+	older := `
+package p
+
+type A struct {
+	B	int
+	C	float64
+}
+
+func D(a int) {
+}
+
+var E int = 5
+`
+
+	newer := `
+package p
+
+type A struct {
+	B	string
+}
+
+func D(a string) {
+}
+`
+
+	changes := cst.Diff(parse(older), parse(newer))
+
+	expected := []string{
+		"source.go:5: p.A.B: changed from int to string",
+		"source.go:6: p.A.C: removed C float64",
+		"source.go:9: p.D.params.0: changed from int to string",
+		"source.go:12: p.E: removed var E int",
+	}
+	if len(changes) != len(expected) {
+		t.Fatalf("Expected %d changes, got %v.", len(expected), changes)
+	}
+	for i, change := range changes {
+		if change.String() != expected[i] {
+			t.Errorf("Expected change %q, got %q.", expected[i], change)
+		}
+	}
+}
+
+func TestChangeSliceElemType(t *testing.T) {
+	older := `
+package p
+
+func A(b []byte) {
+}
+`
+
+	newer := `
+package p
+
+func A(b []rune) {
+}
+`
+
+	testCompare(t, older, newer, true)
+}
+
+func TestChangeMapValueType(t *testing.T) {
+	older := `
+package p
+
+var A map[string]int
+`
+
+	newer := `
+package p
+
+var A map[string]int64
+`
+
+	testCompare(t, older, newer, true)
+}
+
+func TestChangeArrayLength(t *testing.T) {
+	older := `
+package p
+
+type A [4]int
+`
+
+	newer := `
+package p
+
+type A [8]int
+`
+
+	testCompare(t, older, newer, true)
+}
+
+func TestChangeChanDirection(t *testing.T) {
+	older := `
+package p
+
+func A(c chan string) {
+}
+`
+
+	newer := `
+package p
+
+func A(c chan<- string) {
+}
+`
+
+	testCompare(t, older, newer, true)
+}
+
+func TestChangeFuncTypeResults(t *testing.T) {
+	older := `
+package p
+
+type A struct {
+	Callback	func(int) error
+}
+`
+
+	newer := `
+package p
+
+type A struct {
+	Callback	func(int)
+}
+`
+
+	testCompare(t, older, newer, true)
+}
+
+func TestUnchangedCompositeTypes(t *testing.T) {
+	source := `
+package p
+
+type A struct {
+	B	map[string][]int
+	C	[2]chan int
+	D	func(...[]byte) (int, error)
+	E	interface{}
+}
+`
+
+	testCompare(t, source, source, false)
+}
+
+func TestChangeQualifiedParamType(t *testing.T) {
+	older := `
+package p
+
+import "io"
+
+func A(r io.Reader) {
+}
+`
+
+	newer := `
+package p
+
+import "io"
+
+func A(r io.ReadCloser) {
+}
+`
+
+	testCompare(t, older, newer, true)
+}
+
+func TestChangeQualifiedParamPackage(t *testing.T) {
+	older := `
+package p
+
+import "text/template"
+
+func A(t *template.Template) {
+}
+`
+
+	newer := `
+package p
+
+import "html/template"
+
+func A(t *template.Template) {
+}
+`
+
+	testCompare(t, older, newer, true)
+}
+
+func TestRenameImport(t *testing.T) {
+	older := `
+package p
+
+import "io"
+
+func A(r io.Reader) {
+}
+`
+
+	newer := `
+package p
+
+import stdio "io"
+
+func A(r stdio.Reader) {
+}
+`
+
+	testCompare(t, older, newer, false)
+}
+
+func TestFuncRemoveResult(t *testing.T) {
+	older := `
+package p
+
+func A() (int, error) {
+	return 0, nil
+}
+`
+
+	newer := `
+package p
+
+func A() int {
+	return 0
+}
+`
+
+	testCompare(t, older, newer, true)
+}
+
+func TestFuncAddResult(t *testing.T) {
+	older := `
+package p
+
+func A() int {
+	return 0
+}
+`
+
+	newer := `
+package p
+
+func A() (int, error) {
+	return 0, nil
+}
+`
+
+	testCompare(t, older, newer, true)
+}
+
+func TestFuncNamedResults(t *testing.T) {
+	older := `
+package p
+
+func A() (a, b int) {
+	return 0, 0
+}
+`
+
+	newer := `
+package p
+
+func A() (a int) {
+	return 0
+}
+`
+
+	testCompare(t, older, newer, true)
+}
+
+func TestMethodRemoveResults(t *testing.T) {
+	older := `
+package p
+
+type T struct {}
+
+func (T) A() error {
+	return nil
+}
+`
+
+	newer := `
+package p
+
+type T struct {}
+
+func (T) A() {
+}
+`
+
+	changes := cst.Diff(parse(older), parse(newer))
+
+	expected := "source.go:6: p.T.A.results: changed from func (T) A() error to func (T) A()"
+	if len(changes) != 1 || changes[0].String() != expected {
+		t.Errorf("Expected change %q, got %v.", expected, changes)
+	}
+}
+
+func TestIotaConstDoesNotPanic(t *testing.T) {
+	source := `
+package p
+
+const (
+	A = iota
+	B
+)
+
+var C, D = f()
+`
+
+	testCompare(t, source, source, false)
+}
+
+func TestAddMethodToOpenInterface(t *testing.T) {
+	older := `
+package p
+
+type A interface {
+	B() int
+}
+`
+
+	newer := `
+package p
+
+type A interface {
+	B() int
+	C() string
+}
+`
+
+	testCompare(t, older, newer, true)
+}
+
+func TestAddMethodToSealedInterface(t *testing.T) {
+	older := `
+package p
+
+// A is implemented only by this package.
+//gocompat:sealed
+type A interface {
+	B() int
+}
+`
+
+	newer := `
+package p
+
+// A is implemented only by this package.
+//gocompat:sealed
+type A interface {
+	B() int
+	C() string
+}
+`
+
+	testCompare(t, older, newer, false)
+}
+
+func TestAddMethodToInterfaceWithUnexportedMethods(t *testing.T) {
+	older := `
+package p
+
+type A interface {
+	B() int
+	private()
+}
+`
+
+	newer := `
+package p
+
+type A interface {
+	B() int
+	C() string
+	private()
+}
+`
+
+	testCompare(t, older, newer, false)
+}
+
+func TestSealOpenInterface(t *testing.T) {
+	older := `
+package p
+
+type A interface {
+	B() int
+}
+`
+
+	newer := `
+package p
+
+type (
+	//gocompat:sealed
+	A interface {
+		B() int
+	}
+)
+`
+
+	testCompare(t, older, newer, true)
+}
+
+func TestMoveMethodToAnotherType(t *testing.T) {
+	older := `
+package p
+
+type A struct {}
+type B struct {}
+
+func (A) Close() {
+}
+`
+
+	newer := `
+package p
+
+type A struct {}
+type B struct {}
+
+func (B) Close() {
+}
+`
+
+	testCompare(t, older, newer, true)
+}
+
+func TestMethodValueToPointerReciever(t *testing.T) {
+	older := `
+package p
+
+type A struct {}
+
+func (A) Close() {
+}
+`
+
+	newer := `
+package p
+
+type A struct {}
+
+func (*A) Close() {
+}
+`
+
+	testCompare(t, older, newer, true)
+}
+
+func TestMethodPointerToValueReciever(t *testing.T) {
+	older := `
+package p
+
+type A struct {}
+
+func (a *A) Close() {
+}
+`
+
+	newer := `
+package p
+
+type A struct {}
+
+func (a A) Close() {
+}
+`
+
+	testCompare(t, older, newer, false)
+}
+
+func TestAddMethod(t *testing.T) {
+	older := `
+package p
+
+type A struct {}
+`
+
+	newer := `
+package p
+
+type A struct {}
+
+func (A) Close() {
+}
+`
+
+	testCompare(t, older, newer, false)
+}
+
+func TestChangePackageName(t *testing.T) {
+	older := `
+package p
+
+var A int
+`
+
+	newer := `
+package q
+
+var A int
+`
+
+	testCompare(t, older, newer, true)
+}
+
+func TestSymbols(t *testing.T) {
+	source := `
+package p
+
+type A struct {
+	B	int
+}
+
+func (a *A) C() error {
+	return nil
+}
+
+var D = "d"
+`
+
+	expected := []string{
+		"p: package p",
+		"p.A: type A struct{B int}",
+		"p.A.C: func (*A) C() error",
+		"p.D: var D string",
+	}
+
+	symbols := cst.Symbols(parse(source))
+	if strings.Join(symbols, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Expected symbols %q, got %q.", expected, symbols)
+	}
+}
+
+func TestLoosenTypeParamConstraint(t *testing.T) {
+	older := `
+package p
+
+func Sum[T ~int | ~int64](values ...T) T {
+	return 0
+}
+`
+
+	newer := `
+package p
+
+func Sum[T ~int | ~int64 | float64](values ...T) T {
+	return 0
+}
+`
+
+	testCompare(t, older, newer, false)
+}
+
+func TestTightenTypeParamConstraint(t *testing.T) {
+	older := `
+package p
+
+func Sum[T any](values ...T) T {
+	var zero T
+	return zero
+}
+`
+
+	newer := `
+package p
+
+func Sum[T ~int](values ...T) T {
+	return 0
+}
+`
+
+	testCompare(t, older, newer, true)
+}
+
+func TestTightenConstraintInterface(t *testing.T) {
+	older := `
+package p
+
+type Number interface {
+	~int | ~float64
+}
+
+type List[T interface{ ~int | ~float64 }] []T
+`
+
+	newer := `
+package p
+
+type Number interface {
+	~int
+}
+
+type List[T interface{ ~int }] []T
+`
+
+	testCompare(t, older, newer, true)
+}
+
+func TestTightenConstraintEmbeddedInterface(t *testing.T) {
+	older := `
+package p
+
+func Join[T any](values ...T) string {
+	return ""
+}
+`
+
+	newer := `
+package p
+
+import "fmt"
+
+func Join[T interface{ fmt.Stringer }](values ...T) string {
+	return ""
+}
+`
+
+	testCompare(t, older, newer, true)
+}
+
+func TestTightenConstraintEmbeddedComparable(t *testing.T) {
+	older := `
+package p
+
+func Index[T interface{ String() string }](values []T, value T) int {
+	return -1
+}
+`
+
+	newer := `
+package p
+
+func Index[T interface {
+	comparable
+	String() string
+}](values []T, value T) int {
+	return -1
+}
+`
+
+	testCompare(t, older, newer, true)
+}
+
+func TestAddTypeParam(t *testing.T) {
+	older := `
+package p
+
+type Pair[K comparable] struct {
+	Key	K
+}
+`
+
+	newer := `
+package p
+
+type Pair[K comparable, V any] struct {
+	Key	K
+}
+`
+
+	testCompare(t, older, newer, true)
+}
+
+func TestChangeInstantiation(t *testing.T) {
+	older := `
+package p
+
+type List[T any] []T
+
+var A List[int]
+`
+
+	newer := `
+package p
+
+type List[T any] []T
+
+var A List[string]
+`
+
+	testCompare(t, older, newer, true)
+}
+
+func TestRemoveEmbeddedField(t *testing.T) {
+	older := `
+package p
+
+type Base struct {
+	ID	int
+}
+
+type A struct {
+	Base
+}
+`
+
+	newer := `
+package p
+
+type Base struct {
+	ID	int
+}
+
+type A struct {
+}
+`
+
+	testCompare(t, older, newer, true)
+}
+
+func TestAddEmbeddedField(t *testing.T) {
+	older := `
+package p
+
+type Base struct {
+	ID	int
+}
+
+type A struct {
+}
+`
+
+	newer := `
+package p
+
+type Base struct {
+	ID	int
+}
+
+type A struct {
+	*Base
+}
+`
+
+	testCompare(t, older, newer, false)
+}
+
+func TestPromotedMethodRemovedWithEmbeddedType(t *testing.T) {
+	older := `
+package p
+
+type Closer interface {
+	Close() error
+}
+
+type File struct {
+	Closer
+}
+`
+
+	newer := `
+package p
+
+type Closer interface {
+	Close() error
+}
+
+type Flusher interface {
+	Flush() error
+}
+
+type File struct {
+	Closer	Flusher
+}
+`
+
+	changes := cst.BreakingChanges(cst.Diff(parse(older), parse(newer)))
+	paths := []string{}
+	for _, change := range changes {
+		paths = append(paths, change.Path+" "+change.Kind.String())
+	}
+	expected := []string{
+		"p.File.Closer changed",
+		"p.File.Closer changed",
+		"p.File.Close removed",
+	}
+	if strings.Join(paths, ", ") != strings.Join(expected, ", ") {
+		t.Errorf("Expected changes %q, got %q.", expected, paths)
+	}
+}
+
+func TestMoveMethodToEmbeddedType(t *testing.T) {
+	older := `
+package p
+
+type Base struct {
+}
+
+type Client struct {
+	Base
+	Timeout	int
+}
+
+func (Client) Close() error {
+	return nil
+}
+
+func (Client) Reset() {
+}
+
+func (Client) Flush() {
+}
+`
+
+	newer := `
+package p
+
+type Base struct {
+	Timeout	int
+}
+
+func (Base) Close() error {
+	return nil
+}
+
+func (Base) Reset(all bool) {
+}
+
+func (*Base) Flush() {
+}
+
+type Client struct {
+	Base
+}
+`
+
+	changes := cst.BreakingChanges(cst.Diff(parse(older), parse(newer)))
+	paths := []string{}
+	for _, change := range changes {
+		paths = append(paths, change.Path+" "+change.Kind.String())
+	}
+	expected := []string{
+		"p.Client.Timeout removed",
+		"p.Client.Flush changed",
+		"p.Client.Reset.params changed",
+	}
+	if strings.Join(paths, ", ") != strings.Join(expected, ", ") {
+		t.Errorf("Expected changes %q, got %q.", expected, paths)
+	}
+}
+
+func TestAmbiguousPromotedField(t *testing.T) {
+	older := `
+package p
+
+type B struct {
+	ID	int
+}
+
+type C struct {
+	Name	string
+}
+
+type A struct {
+	B
+	C
+}
+`
+
+	newer := `
+package p
+
+type B struct {
+	ID	int
+}
+
+type C struct {
+	ID	int
+	Name	string
+}
+
+type A struct {
+	B
+	C
+}
+`
+
+	changes := cst.BreakingChanges(cst.Diff(parse(older), parse(newer)))
+	if len(changes) != 1 || changes[0].Path != "p.A.ID" {
+		t.Errorf("Expected the ambiguous field to be removed, got %v.", changes)
+	}
+}
+
+func TestInlineEmbeddedInterface(t *testing.T) {
+	older := `
+package p
+
+type Reader interface {
+	Read(p []byte) (int, error)
+}
+
+type ReadCloser interface {
+	Reader
+	Close() error
+}
+`
+
+	newer := `
+package p
+
+type Reader interface {
+	Read(p []byte) (int, error)
+}
+
+type ReadCloser interface {
+	Read(p []byte) (int, error)
+	Close() error
+}
+`
+
+	testCompare(t, older, newer, false)
+}
+
+func TestRemoveEmbeddedInterface(t *testing.T) {
+	older := `
+package p
+
+type Reader interface {
+	Read(p []byte) (int, error)
+}
+
+type ReadCloser interface {
+	Reader
+	Close() error
+}
+`
+
+	newer := `
+package p
+
+type Reader interface {
+	Read(p []byte) (int, error)
+}
+
+type ReadCloser interface {
+	Close() error
+}
+`
+
+	testCompare(t, older, newer, true)
+}
+
+func TestEmbedExternalInterface(t *testing.T) {
+	older := `
+package p
+
+type ReadCloser interface {
+	Close() error
+}
+`
+
+	newer := `
+package p
+
+import "io"
+
+type ReadCloser interface {
+	io.Reader
+	Close() error
+}
+`
+
+	testCompare(t, older, newer, true)
+}
+
+func TestEmbeddedInterfaceFromAnotherPackage(t *testing.T) {
+	older := map[string]string{
+		"q/q.go": `
+package q
+
+type Reader interface {
+	Read(p []byte) (int, error)
+}
+`,
+		"r.go": `
+package p
+
+import "p/q"
+
+type ReadCloser interface {
+	q.Reader
+	Close() error
+}
+`,
+	}
+
+	newer := map[string]string{
+		"q/q.go": `
+package q
+
+type Reader interface {
+	Read(p []byte) (int, error)
+	Reset()
+}
+`,
+		"r.go": older["r.go"],
+	}
+
+	changes := cst.BreakingChanges(cst.Diff(parseFiles(older), parseFiles(newer)))
+	paths := []string{}
+	for _, change := range changes {
+		paths = append(paths, change.Path)
+	}
+	if strings.Join(paths, ", ") != "p/q.Reader.Reset" {
+		t.Errorf("Expected the method to be reported once where it is declared, got %q.", paths)
+	}
+}
+
+func TestChangeConstValue(t *testing.T) {
+	older := `
+package p
+
+const MaxSize = 1024
+`
+
+	newer := `
+package p
+
+const MaxSize = 2048
+`
+
+	testCompare(t, older, newer, true)
+
+	options := cst.DefaultOptions()
+	options.ConstValues = cst.Compatible
+	changes := cst.DiffWith(parse(older), parse(newer), options)
+	expected := "source.go:4: p.MaxSize: value changed from const MaxSize untyped int = 1024 to const MaxSize untyped int = 2048 (compatible)"
+	if len(changes) != 1 || changes[0].String() != expected {
+		t.Errorf("Expected change %q, got %v.", expected, changes)
+	}
+}
+
+func TestReorderIotaConsts(t *testing.T) {
+	older := `
+package p
+
+type Status int
+
+const (
+	StatusOK Status = iota
+	StatusFailed
+)
+`
+
+	newer := `
+package p
+
+type Status int
+
+const (
+	StatusUnknown Status = iota
+	StatusOK
+	StatusFailed
+)
+`
+
+	changes := cst.BreakingChanges(cst.Diff(parse(older), parse(newer)))
+	paths := []string{}
+	for _, change := range changes {
+		paths = append(paths, change.Path+" "+change.Kind.String())
+	}
+	expected := "p.StatusFailed value changed, p.StatusOK value changed"
+	if strings.Join(paths, ", ") != expected {
+		t.Errorf("Expected changes %q, got %q.", expected, paths)
+	}
+}
+
+func TestConstToVar(t *testing.T) {
+	older := `
+package p
+
+const A = 1
+`
+
+	newer := `
+package p
+
+var A = 1
+`
+
+	testCompare(t, older, newer, true)
+}
+
+func TestTypeDefinitionToAlias(t *testing.T) {
+	older := `
+package p
+
+type B struct{}
+
+type A B
+`
+
+	newer := `
+package p
+
+type B struct{}
+
+type A = B
+`
+
+	testCompare(t, older, newer, true)
+	testCompare(t, newer, older, true)
+}
+
+func TestMethodDeclaredBeforeAlias(t *testing.T) {
+	older := `
+package p
+
+func (Alias) Close() error {
+	return nil
+}
+
+type Alias = Client
+
+type Client struct{}
+`
+
+	newer := `
+package p
+
+type Client struct{}
+
+type Alias = Client
+`
+
+	changes := []string{}
+	for _, change := range cst.BreakingChanges(cst.Diff(parse(older), parse(newer))) {
+		changes = append(changes, change.String())
+	}
+	expected := []string{
+		"source.go:4: p.Client.Close: removed func (Alias) Close() error",
+	}
+	if strings.Join(changes, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Expected changes %q, got %q.", expected, changes)
+	}
+}
+
+func TestChangeAliasTargetToIdenticalType(t *testing.T) {
+	older := `
+package p
+
+type B struct{}
+
+type C = B
+
+type A = B
+`
+
+	newer := `
+package p
+
+type B struct{}
+
+type C = B
+
+type A = C
+`
+
+	testCompare(t, older, newer, false)
+}
+
+func TestChangeAliasTarget(t *testing.T) {
+	older := `
+package p
+
+type A = int
+`
+
+	newer := `
+package p
+
+type A = int64
+`
+
+	testCompare(t, older, newer, true)
+}
+
+func TestReplaceParamTypeWithAlias(t *testing.T) {
+	older := `
+package p
+
+type Options struct{}
+
+type Config = Options
+
+func New(o *Options) []Options {
+	return nil
+}
+`
+
+	newer := `
+package p
+
+type Options struct{}
+
+type Config = Options
+
+func New(o *Config) []Config {
+	return nil
+}
+`
+
+	testCompare(t, older, newer, false)
+}
+
+func TestReplaceFieldTypeWithAliasFromAnotherPackage(t *testing.T) {
+	older := map[string]string{
+		"q/q.go": `
+package q
+
+type B struct{}
+`,
+		"a.go": `
+package p
+
+import "p/q"
+
+type A struct {
+	B	map[string]q.B
+}
+`,
+	}
+
+	newer := map[string]string{
+		"q/q.go": older["q/q.go"],
+		"a.go": `
+package p
+
+import "p/q"
+
+type B = q.B
+
+type A struct {
+	B	map[string]B
+}
+`,
+	}
+
+	changes := cst.BreakingChanges(cst.Diff(parseFiles(older), parseFiles(newer)))
+	if len(changes) != 0 {
+		t.Errorf("Expected no breaking changes, got %v.", changes)
+	}
+}
+
+func TestAddFieldToStructWithExportedFields(t *testing.T) {
+	older := `
+package p
+
+type Point struct {
+	X, Y	int
+}
+
+type Options struct {
+	Name	string
+	debug	bool
+}
+`
+
+	newer := `
+package p
+
+type Point struct {
+	X, Y, Z	int
+}
+
+type Options struct {
+	Name	string
+	Size	int
+	debug	bool
+}
+`
+
+	changes := cst.Diff(parse(older), parse(newer))
+	compatibilities := map[string]cst.Compatibility{}
+	for _, change := range changes {
+		compatibilities[change.Path] = change.Compatibility
+	}
+	if compatibilities["p.Point.Z"] != cst.PotentiallyBreaking {
+		t.Errorf("Expected field added to Point to be potentially breaking, got %v.", compatibilities["p.Point.Z"])
+	}
+	if compatibilities["p.Options.Size"] != cst.Compatible {
+		t.Errorf("Expected field added to Options to be compatible, got %v.", compatibilities["p.Options.Size"])
+	}
+
+	options := cst.DefaultOptions()
+	options.FieldAdditions = cst.Breaking
+	if len(cst.BreakingChanges(cst.DiffWith(parse(older), parse(newer), options))) != 1 {
+		t.Error("Expected the field added to Point to be breaking.")
+	}
+}
+
+func TestStructLosesComparability(t *testing.T) {
+	older := `
+package p
+
+type Key struct {
+	name	string
+}
+`
+
+	newer := `
+package p
+
+type Key struct {
+	name	string
+	parts	[]string
+}
+`
+
+	changes := cst.BreakingChanges(cst.Diff(parse(older), parse(newer)))
+	expected := "source.go:4: p.Key: changed from comparable to not comparable"
+	if len(changes) != 1 || changes[0].String() != expected {
+		t.Errorf("Expected change %q, got %v.", expected, changes)
+	}
+}
+
+func TestStructLosesComparabilityTransitively(t *testing.T) {
+	older := map[string]string{
+		"q/q.go": `
+package q
+
+type Handler struct {
+	name	string
+}
+`,
+		"a.go": `
+package p
+
+import "p/q"
+
+type Route struct {
+	handlers	[2]q.Handler
+}
+`,
+	}
+
+	newer := map[string]string{
+		"q/q.go": `
+package q
+
+type Handler struct {
+	name	string
+	fn	func()
+}
+`,
+		"a.go": older["a.go"],
+	}
+
+	changes := cst.BreakingChanges(cst.Diff(parseFiles(older), parseFiles(newer)))
+	paths := []string{}
+	for _, change := range changes {
+		paths = append(paths, change.Path)
+	}
+	if strings.Join(paths, ", ") != "p.Route, p/q.Handler" {
+		t.Errorf("Expected both types to lose comparability, got %q.", paths)
+	}
+}
+
+func TestChangeStructTags(t *testing.T) {
+	older := `
+package p
+
+type User struct {
+	ID	int	` + "`json:\"user_id\" db:\"id\"`" + `
+	Name	string	` + "`json:\"name\" yaml:\"name\"`" + `
+	Email	string	` + "`json:\"email\" mapstructure:\"email\"`" + `
+	Age	int
+}
+`
+
+	newer := `
+package p
+
+type User struct {
+	ID	int	` + "`json:\"userId\" db:\"id\"`" + `
+	Name	string	` + "`json:\"name,omitempty\"`" + `
+	Email	string	` + "`json:\"email\" mapstructure:\"mail\"`" + `
+	Age	int	` + "`json:\"age\" db:\"age\"`" + `
+}
+`
+
+	options := cst.DefaultOptions()
+	options.TagKeys = append(options.TagKeys, "mapstructure")
+	descriptions := []string{}
+	for _, change := range cst.DiffWith(parse(older), parse(newer), options) {
+		descriptions = append(descriptions, change.Path+": "+change.Kind.String()+" "+change.Compatibility.String())
+	}
+
+	expected := []string{
+		"p.User.Age.tag.json: added breaking",
+		"p.User.Age.tag.db: added compatible",
+		"p.User.Email.tag.mapstructure: changed breaking",
+		"p.User.ID.tag.json: changed breaking",
+		"p.User.Name.tag.json: changed breaking",
+		"p.User.Name.tag.yaml: removed compatible",
+	}
+	if strings.Join(descriptions, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Expected changes %q, got %q.", expected, descriptions)
+	}
+}
+
+func TestAppendVariadicParam(t *testing.T) {
+	older := `
+package p
+
+type Option int
+
+type Client struct {
+}
+
+type Doer interface {
+	Do(a int)
+}
+
+func New(a int) {
+}
+
+func Log(msg string) {
+}
+
+func Split(a int) {
+}
+
+func (c *Client) Do(a int) {
+}
+`
+
+	newer := `
+package p
+
+type Option int
+
+type Client struct {
+}
+
+type Doer interface {
+	Do(a int, opts ...Option)
+}
+
+func New(a int, opts ...Option) {
+}
+
+func Log(msg ...string) {
+}
+
+func Split(a []int) {
+}
+
+func (c *Client) Do(a int, opts ...Option) {
+}
+`
+
+	descriptions := []string{}
+	for _, change := range cst.Diff(parse(older), parse(newer)) {
+		descriptions = append(descriptions, change.Path+": "+change.Compatibility.String())
+	}
+
+	expected := []string{
+		"p.Client.Do.params: potentially breaking",
+		"p.Doer.Do.params: breaking",
+		"p.Log.params: potentially breaking",
+		"p.New.params: potentially breaking",
+		"p.Split.params.0: breaking",
+	}
+	if strings.Join(descriptions, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Expected changes %q, got %q.", expected, descriptions)
+	}
+
+	options := cst.DefaultOptions()
+	options.FuncValues = cst.Compatible
+	if len(cst.BreakingChanges(cst.DiffWith(parse(older), parse(newer), options))) != 2 {
+		t.Error("Expected only the interface method and the slice parameter to be breaking.")
+	}
+}
+
+func TestRelaxInterfaceParams(t *testing.T) {
+	older := `
+package p
+
+type Reader interface {
+	Read(p []byte) (int, error)
+}
+
+type ReadCloser interface {
+	Reader
+	Close() error
+}
+
+type File struct {
+}
+
+func (f *File) Read(p []byte) (int, error) {
+	return 0, nil
+}
+
+type Copier interface {
+	Copy(f *File)
+}
+
+func Open(f *File) {
+}
+
+func Wrap(r ReadCloser) {
+}
+
+func Name(f File) {
+}
+
+func Source() Reader {
+	return nil
+}
+
+func Create() Reader {
+	return nil
+}
+`
+
+	newer := `
+package p
+
+type Reader interface {
+	Read(p []byte) (int, error)
+}
+
+type ReadCloser interface {
+	Reader
+	Close() error
+}
+
+type File struct {
+}
+
+func (f *File) Read(p []byte) (int, error) {
+	return 0, nil
+}
+
+type Copier interface {
+	Copy(r Reader)
+}
+
+func Open(r Reader) {
+}
+
+func Wrap(r Reader) {
+}
+
+func Name(r Reader) {
+}
+
+func Source() ReadCloser {
+	return nil
+}
+
+func Create() *File {
+	return nil
+}
+`
+
+	descriptions := []string{}
+	for _, change := range cst.Diff(parse(older), parse(newer)) {
+		descriptions = append(descriptions, change.Path+": "+change.Compatibility.String()+" "+change.Note)
+	}
+
+	expected := []string{
+		"p.Copier.Copy.params.0: breaking *File implements Reader",
+		"p.Create.results.0: breaking ",
+		"p.Name.params.0: breaking ",
+		"p.Open.params.0: potentially breaking *File implements Reader",
+		"p.Source.results.0: potentially breaking ReadCloser implements Reader",
+		"p.Wrap.params.0: potentially breaking ReadCloser implements Reader",
+	}
+	if strings.Join(descriptions, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Expected changes %q, got %q.", expected, descriptions)
+	}
+}
+
+func TestPromoteFromUnexportedType(t *testing.T) {
+	older := `
+package p
+
+type base struct {
+	Timeout	int
+}
+
+func (*base) Close() {
+}
+
+func (*base) Reset(all bool) {
+}
+
+type Client struct {
+	*base
+}
+`
+
+	newer := `
+package p
+
+type base struct {
+}
+
+func (*base) Reset() {
+}
+
+type Client struct {
+	*base
+}
+`
+
+	for name, project := range map[string]func(string) *cst.Project{
+		"syntax": parse,
+		"types": func(source string) *cst.Project {
+			return scanTypes(t, map[string]string{"source.go": source})
+		},
+	} {
+		paths := []string{}
+		for _, change := range cst.BreakingChanges(cst.Diff(project(older), project(newer))) {
+			paths = append(paths, change.Path+": "+change.Kind.String())
+		}
+		expected := "p.Client.Close: removed, p.Client.Reset.params: changed, p.Client.Timeout: removed"
+		if strings.Join(paths, ", ") != expected {
+			t.Errorf("Expected %s changes %q, got %q.", name, expected, paths)
+		}
+	}
+}
+
+func TestEmbedUnexportedInterface(t *testing.T) {
+	older := `
+package p
+
+type closer interface {
+	Close() error
+}
+
+type Conn interface {
+	closer
+	Read() int
+}
+`
+
+	newer := `
+package p
+
+type closer interface {
+	Flush() error
+}
+
+type Conn interface {
+	closer
+	Read() int
+}
+`
+
+	paths := []string{}
+	for _, change := range cst.BreakingChanges(cst.Diff(parse(older), parse(newer))) {
+		paths = append(paths, change.Path+": "+change.Kind.String())
+	}
+	if strings.Join(paths, ", ") != "p.Conn.Close: removed, p.Conn.Flush: added" {
+		t.Errorf("Expected the methods of the unexported interface to be compared, got %q.", paths)
+	}
+}
+
+func TestLoseComparabilityThroughUnexportedType(t *testing.T) {
+	older := `
+package p
+
+type ident struct {
+	name	string
+}
+
+type Key struct {
+	id	ident
+}
+`
+
+	newer := `
+package p
+
+type ident struct {
+	name	string
+	parts	[]int
+}
+
+type Key struct {
+	id	ident
+}
+`
+
+	changes := cst.BreakingChanges(cst.Diff(parse(older), parse(newer)))
+	if len(changes) != 1 || changes[0].Path != "p.Key" {
+		t.Errorf("Expected Key to lose comparability, got %v.", changes)
+	}
+}
+
+func TestRenameTypeParams(t *testing.T) {
+	older := `
+package p
+
+type List[T any] struct {
+	Items	[]T
+	next	*List[T]
+}
+
+func (l *List[T]) Get(i int) T {
+	return l.Items[i]
+}
+
+type Pair[K comparable, V any] = map[K]V
+
+func Map[T, U any](v []T, f func(T) U) []U {
+	return nil
+}
+`
+
+	newer := `
+package p
+
+type List[E any] struct {
+	Items	[]E
+	next	*List[E]
+}
+
+func (l *List[V]) Get(i int) V {
+	return l.Items[i]
+}
+
+type Pair[A comparable, B any] = map[A]B
+
+func Map[In, Out any](v []In, f func(In) Out) []Out {
+	return nil
+}
+`
+
+	for name, project := range map[string]func(string) *cst.Project{
+		"syntax": parse,
+		"types": func(source string) *cst.Project {
+			return scanTypes(t, map[string]string{"source.go": source})
+		},
+	} {
+		if changes := cst.Diff(project(older), project(newer)); len(changes) != 0 {
+			t.Errorf("Expected renamed type parameters to be compatible with %s, got %v.", name, changes)
+		}
+	}
+}
+
+func TestRenameTypeParamsWithChanges(t *testing.T) {
+	older := `
+package p
+
+func First[T any](v []T) T {
+	return v[0]
+}
+`
+
+	newer := `
+package p
+
+func First[E any](v E) E {
+	return v
+}
+`
+
+	changes := cst.BreakingChanges(cst.Diff(parse(older), parse(newer)))
+	expected := "source.go:4: p.First.params.0: changed from []T to T"
+	if len(changes) != 1 || changes[0].String() != expected {
+		t.Errorf("Expected change %q, got %v.", expected, changes)
+	}
+}
+
+func TestRelaxBasicParamToInterface(t *testing.T) {
+	older := `
+package p
+
+type Stringer interface {
+	String() string
+}
+
+type Code int
+
+func (c Code) String() string {
+	return ""
+}
+
+func F(c Code) {
+}
+`
+
+	newer := `
+package p
+
+type Stringer interface {
+	String() string
+}
+
+type Code int
+
+func (c Code) String() string {
+	return ""
+}
+
+func F(s Stringer) {
+}
+`
+
+	options := cst.DefaultOptions()
+	options.FuncValues = cst.Compatible
+	changes := cst.BreakingChanges(cst.DiffWith(parse(older), parse(newer), options))
+	expected := "source.go:14: p.F.params.0: changed from Code to Stringer " +
+		"(breaking, Code implements Stringer, but untyped constants can not be passed anymore)"
+	if len(changes) != 1 || changes[0].String() != expected {
+		t.Errorf("Expected change %q, got %v.", expected, changes)
+	}
+}
+
+func TestRelaxBasicParamToEmptyInterface(t *testing.T) {
+	older := `
+package p
+
+type Code int
+
+func F(c Code) {
+}
+
+func G(n int) {
+}
+`
+
+	newer := `
+package p
+
+type Code int
+
+func F(c interface{}) {
+}
+
+func G(n any) {
+}
+`
+
+	options := cst.DefaultOptions()
+	options.FuncValues = cst.Compatible
+	changes := []string{}
+	for _, change := range cst.DiffWith(parse(older), parse(newer), options) {
+		changes = append(changes, change.String())
+	}
+	expected := []string{
+		"source.go:6: p.F.params.0: changed from Code to interface{} (compatible, Code implements interface{})",
+		"source.go:9: p.G.params.0: changed from int to any (compatible, int implements any)",
+	}
+	if strings.Join(changes, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Expected changes %q, got %q.", expected, changes)
+	}
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+
+	"github.com/s2gatev/gocompat/cst"
+)
+
+// Compatibility index formats.
+const (
+	// jsonFormat is the canonical JSON format, readable in code reviews.
+	jsonFormat = "json"
+
+	// gobFormat is the binary format used by earlier versions.
+	gobFormat = "gob"
+)
+
+// encodeIndex encodes a project in the given index format.
+func encodeIndex(project *cst.Project, format string) ([]byte, error) {
+	switch format {
+	case jsonFormat:
+		return cst.MarshalJSON(project)
+	case gobFormat:
+		buffer := bytes.Buffer{}
+		if err := gob.NewEncoder(&buffer).Encode(project); err != nil {
+			return nil, err
+		}
+		return buffer.Bytes(), nil
+	default:
+		return nil, fmt.Errorf("unknown index format %q", format)
+	}
+}
+
+// decodeIndex decodes a project, detecting the format of the index.
+func decodeIndex(content []byte) (*cst.Project, error) {
+	if trimmed := bytes.TrimSpace(content); len(trimmed) > 0 && trimmed[0] == '{' {
+		return cst.UnmarshalJSON(content)
+	}
+
+	project := &cst.Project{}
+	if err := gob.NewDecoder(bytes.NewReader(content)).Decode(project); err != nil {
+		return nil, err
+	}
+	return project, nil
+}
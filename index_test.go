@@ -0,0 +1,62 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/s2gatev/gocompat/cst"
+)
+
+const indexSource = `
+package p
+
+import "io"
+
+type A struct {
+	B	map[string][]int
+	C	<-chan io.Reader
+}
+
+func (a *A) D(e ...int) (bool, error) {
+	return false, nil
+}
+
+type E interface {
+	F(func(int) string)
+	private()
+}
+
+var G [4]*A
+`
+
+func TestIndexRoundTrip(t *testing.T) {
+	project := parse(indexSource)
+
+	for _, format := range []string{jsonFormat, gobFormat} {
+		content, err := encodeIndex(project, format)
+		if err != nil {
+			t.Fatalf("Error when encoding %s index: %v", format, err)
+		}
+
+		decoded, err := decodeIndex(content)
+		if err != nil {
+			t.Fatalf("Error when decoding %s index: %v", format, err)
+		}
+
+		if changes := cst.Diff(project, decoded); len(changes) != 0 {
+			t.Errorf("Unexpected changes after %s round trip: %v", format, changes)
+		}
+		if changes := cst.Diff(decoded, project); len(changes) != 0 {
+			t.Errorf("Unexpected changes after %s round trip: %v", format, changes)
+		}
+	}
+}
+
+func TestJSONIndexIsDeterministic(t *testing.T) {
+	first, _ := encodeIndex(parse(indexSource), jsonFormat)
+	second, _ := encodeIndex(parse(indexSource), jsonFormat)
+
+	if !bytes.Equal(first, second) {
+		t.Error("Expected identical JSON indexes for the same source.")
+	}
+}
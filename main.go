@@ -1,63 +1,89 @@
 package main
 
 import (
-	"bytes"
-	"encoding/gob"
 	"flag"
 	"fmt"
-	"go/parser"
-	"go/token"
 	"io/ioutil"
 	"os"
-	"path/filepath"
-	"regexp"
 
 	"github.com/s2gatev/gocompat/cst"
 )
 
 const compatIndexFileName = ".gocompat"
 
-var goFilePattern = regexp.MustCompile(`^.*\.go$`)
-
-var context = &InterfaceContext{
-	Project: &cst.Project{Packages: map[string]*cst.Package{}},
-}
-
-// Flags.
+// Flags of the implicit command, kept for compatibility with earlier versions.
 var (
 	forceStore = flag.Bool("f", false, "Store compatibility index even if the current API is not compatible with the previous version.")
+	options    = newScanOptions(flag.CommandLine)
+	format     = flag.String("format", jsonFormat, "Format of the stored compatibility index - \"json\" or the legacy \"gob\".")
+	compare    = newCompareOptions(flag.CommandLine)
+	base       = flag.String("base", "", "Compare against the project at a git revision, e.g. \"v1.4.0\", instead of the stored index. The index is not updated.")
 )
 
-func process(path string, f os.FileInfo, err error) error {
-	if goFilePattern.Match([]byte(path)) {
-		fileSet := token.NewFileSet()
-		fileContent, _ := ioutil.ReadFile(path)
-		file, _ := parser.ParseFile(fileSet, path, fileContent, parser.ParseComments)
+// printChanges prints the breaking changes between two versions of the
+// project and returns if they are compatible.
+func printChanges(older, newer *cst.Project, options cst.Options) bool {
+	report, err := compare.report(older, newer, options)
+	if err != nil {
+		fmt.Println("Error when scanning consumers.", err)
+		return false
+	}
+	report.WriteText(os.Stdout, false)
+	return report.Compatible
+}
 
-		ProcessFile(fileSet, file, context)
+func main() {
+	if len(os.Args) > 1 {
+		if cmd := findCommand(os.Args[1]); cmd != nil {
+			os.Exit(cmd.Run(os.Args[2:]))
+		}
 	}
 
-	return nil
+	flag.Usage = usage
+	flag.Parse()
+	os.Exit(runImplicit())
 }
 
-func main() {
+// runImplicit compares the current API to the index and stores it if it is
+// compatible, as done by earlier versions without subcommands.
+func runImplicit() int {
 	exitMessage := ""
 	exitCode := 0
 	shouldStoreIndex := true
 
-	// Parse command-line options.
-	flag.Parse()
+	diffOptions, err := compare.options()
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
 
 	// Scan project files.
-	filepath.Walk(".", process)
+	current, err := options.scanDir(".")
+	if err != nil {
+		fmt.Println("Error when scanning project files.", err)
+		return 1
+	}
+
+	// If base revision is given compare current API to it without using the index.
+	if *base != "" {
+		older, err := options.scanRevision(".", *base)
+		if err != nil {
+			fmt.Println("Error when scanning base revision.", err)
+			return 1
+		}
+
+		if printChanges(older, current, diffOptions) {
+			fmt.Println("OK")
+			return 0
+		}
+		fmt.Println("Not OK")
+		return 1
+	}
 
 	// If index is present compare current API to the previous version.
 	if content, err := ioutil.ReadFile(compatIndexFileName); err == nil {
-		older := &cst.Project{}
-		decoder := gob.NewDecoder(bytes.NewReader([]byte(content)))
-
-		if err = decoder.Decode(older); err == nil {
-			if ok := older.Compare(context.Project); ok {
+		if older, err := decodeIndex(content); err == nil {
+			if printChanges(older, current, diffOptions) {
 				exitMessage = "OK"
 			} else {
 				exitMessage = "Not OK"
@@ -65,21 +91,17 @@ func main() {
 				shouldStoreIndex = false
 			}
 		} else {
-			fmt.Println("Error when decoding compatibility index.")
+			fmt.Println("Error when decoding compatibility index.", err)
 		}
 	}
 
 	// Store context objects in index.
 	if shouldStoreIndex || *forceStore {
-		buffer := bytes.Buffer{}
-		encoder := gob.NewEncoder(&buffer)
-		err := encoder.Encode(context.Project)
-		if err != nil {
+		if err := writeIndex(compatIndexFileName, current, *format); err != nil {
 			fmt.Println("Error when encoding compatibility index.", err)
 		}
-		ioutil.WriteFile(compatIndexFileName, buffer.Bytes(), 0644)
 	}
 
 	fmt.Println(exitMessage)
-	os.Exit(exitCode)
+	return exitCode
 }
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const moduleFileName = "go.mod"
+
+// readModulePath returns the module path declared in the go.mod file of a
+// directory. Projects without go.mod are assumed to be imported by the name
+// of their directory.
+func readModulePath(dir string) string {
+	if file, err := os.Open(filepath.Join(dir, moduleFileName)); err == nil {
+		defer file.Close()
+		if path := parseModulePath(file); path != "" {
+			return path
+		}
+	}
+
+	if abs, err := filepath.Abs(dir); err == nil {
+		return filepath.Base(abs)
+	}
+	return dir
+}
+
+// parseModulePath extracts the module path from the content of a go.mod file.
+func parseModulePath(r io.Reader) string {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = line[:i]
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) != 2 || fields[0] != "module" {
+			continue
+		}
+
+		if path, err := strconv.Unquote(fields[1]); err == nil {
+			return path
+		}
+		return fields[1]
+	}
+	return ""
+}
@@ -0,0 +1,20 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseModulePath(t *testing.T) {
+	cases := map[string]string{
+		"module github.com/a/b\n\ngo 1.21\n":          "github.com/a/b",
+		"// comment\nmodule \"example.com/c\" // x\n": "example.com/c",
+		"go 1.21\n": "",
+	}
+
+	for content, expected := range cases {
+		if actual := parseModulePath(strings.NewReader(content)); actual != expected {
+			t.Errorf("Expected module path %q, got %q.", expected, actual)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/s2gatev/gocompat/cst"
+)
+
+// Report is the result of comparing two versions of the project interface.
+type Report struct {
+	Compatible bool         `json:"compatible"`
+	Bump       Bump         `json:"bump"`
+	Changes    []cst.Change `json:"changes"`
+}
+
+// newReport compares two versions of the project interface.
+func newReport(older, newer *cst.Project, options cst.Options) *Report {
+	return reportChanges(cst.DiffWith(older, newer, options))
+}
+
+// reportChanges summarizes the changes between two versions of the project
+// interface.
+func reportChanges(changes []cst.Change) *Report {
+	return &Report{
+		Compatible: len(cst.BreakingChanges(changes)) == 0,
+		Bump:       recommendBump(changes),
+		Changes:    changes,
+	}
+}
+
+// WriteText writes the changes one per line. Unless all is set only
+// breaking and potentially breaking changes are written.
+func (r *Report) WriteText(w io.Writer, all bool) {
+	for _, change := range r.Changes {
+		if all || change.Compatibility != cst.Compatible {
+			fmt.Fprintln(w, change)
+		}
+	}
+}
+
+// WriteJSON writes the report as an indented JSON document.
+func (r *Report) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "\t")
+	return encoder.Encode(r)
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/s2gatev/gocompat/cst"
+)
+
+func TestReportJSON(t *testing.T) {
+	older := `
+package p
+
+type A struct {
+	B	int
+}
+
+func C() {
+}
+`
+
+	newer := `
+package p
+
+type A struct {
+	B	string
+	D	int
+}
+
+func (A) E() {
+}
+`
+
+	report := newReport(parse(older), parse(newer), cst.DefaultOptions())
+	if report.Compatible {
+		t.Error("Expected incompatible report.")
+	}
+
+	buffer := bytes.Buffer{}
+	if err := report.WriteJSON(&buffer); err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded struct {
+		Compatible bool
+		Changes    []map[string]string
+	}
+	if err := json.Unmarshal(buffer.Bytes(), &decoded); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []map[string]string{
+		{"path": "p.A.B", "package": "p", "symbol": "field", "kind": "changed",
+			"compatibility": "breaking", "old": "int", "new": "string", "position": "source.go:5"},
+		{"path": "p.A.D", "package": "p", "symbol": "field", "kind": "added",
+			"compatibility": "potentially breaking", "new": "D int", "position": "source.go:6"},
+		{"path": "p.A.E", "package": "p", "symbol": "method", "kind": "added",
+			"compatibility": "compatible", "new": "func (A) E()", "position": "source.go:9"},
+		{"path": "p.C", "package": "p", "symbol": "func", "kind": "removed",
+			"compatibility": "breaking", "old": "func C()", "position": "source.go:8"},
+	}
+	if len(decoded.Changes) != len(expected) {
+		t.Fatalf("Expected %d changes, got %v.", len(expected), decoded.Changes)
+	}
+	for i, change := range decoded.Changes {
+		for key, value := range expected[i] {
+			if change[key] != value {
+				t.Errorf("Expected %s of change %d to be %q, got %q.", key, i, value, change[key])
+			}
+		}
+		if len(change) != len(expected[i]) {
+			t.Errorf("Unexpected fields in change %d: %v.", i, change)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"fmt"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// FileFilter selects the files that are part of the project interface. By
+// default it follows the file selection rules of go build, ignoring tests,
+// testdata, vendored packages and hidden directories.
+type FileFilter struct {
+	// Root is the directory the filtered paths are relative to.
+	Root string
+
+	// Build is used to evaluate build constraints. Defaults to build.Default.
+	Build *build.Context
+
+	// Include restricts scanning to files matching at least one pattern.
+	Include []string
+
+	// Exclude skips files and directories matching any pattern.
+	Exclude []string
+}
+
+// matchPattern matches a slash-separated path against a glob pattern.
+// Patterns ending in "/..." match a directory and everything below it.
+func matchPattern(pattern, name string) bool {
+	if prefix := strings.TrimSuffix(pattern, "/..."); prefix != pattern {
+		return name == prefix || strings.HasPrefix(name, prefix+"/")
+	}
+	matched, _ := path.Match(pattern, name)
+	return matched
+}
+
+// matchAny returns if a path or its base name matches any of the patterns.
+func matchAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if matchPattern(pattern, name) || matchPattern(pattern, path.Base(name)) {
+			return true
+		}
+	}
+	return false
+}
+
+// SkipDir returns if a directory should not be scanned.
+func (ff *FileFilter) SkipDir(dir string) bool {
+	dir = filepath.ToSlash(dir)
+	if dir == "." {
+		return false
+	}
+
+	base := path.Base(dir)
+	if strings.HasPrefix(base, ".") || strings.HasPrefix(base, "_") ||
+		base == "testdata" || base == "vendor" {
+		return true
+	}
+	return matchAny(ff.Exclude, dir)
+}
+
+// Match returns if a file should be scanned. The path is relative to the root.
+func (ff *FileFilter) Match(file string) bool {
+	name := filepath.ToSlash(file)
+	if !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+		return false
+	}
+	if len(ff.Include) > 0 && !matchAny(ff.Include, name) {
+		return false
+	}
+	if matchAny(ff.Exclude, name) {
+		return false
+	}
+
+	// Check file name prefixes, GOOS/GOARCH suffixes and build constraints.
+	buildContext := ff.Build
+	if buildContext == nil {
+		buildContext = &build.Default
+	}
+	dir, base := filepath.Split(file)
+	matched, err := buildContext.MatchFile(filepath.Join(ff.Root, dir), base)
+	return err == nil && matched
+}
+
+// splitPatterns splits a comma-separated list of patterns.
+func splitPatterns(list string) []string {
+	var patterns []string
+	for _, pattern := range strings.Split(list, ",") {
+		if pattern = strings.TrimSpace(pattern); pattern != "" {
+			patterns = append(patterns, pattern)
+		}
+	}
+	return patterns
+}
+
+// processSource parses a Go source file and adds its symbols to the context.
+// Files with syntax errors are reported on stderr, so that they do not mix
+// with reports written to stdout, and skipped.
+func processSource(path string, content []byte, context *InterfaceContext) {
+	fileSet := token.NewFileSet()
+	if context.TypeCheck {
+		if context.FileSet == nil {
+			context.FileSet = token.NewFileSet()
+		}
+		fileSet = context.FileSet
+	}
+
+	file, err := parser.ParseFile(fileSet, path, content, parser.ParseComments)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error when parsing file.", err)
+		return
+	}
+
+	if context.TypeCheck {
+		context.addFile(file)
+		return
+	}
+	ProcessFile(fileSet, file, context)
+}
+
+// scanDir adds the symbols of all project files in a directory to the context.
+func scanDir(root string, filter *FileFilter, context *InterfaceContext) error {
+	err := filepath.Walk(root, func(path string, f os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+
+		if f.IsDir() {
+			if filter.SkipDir(rel) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if filter.Match(rel) {
+			content, err := ioutil.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			processSource(rel, content, context)
+		}
+
+		return nil
+	})
+	if err == nil && context.TypeCheck {
+		context.checkTypes()
+	}
+	return err
+}
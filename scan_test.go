@@ -0,0 +1,67 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileFilter(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "gocompat")
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"a.go":             "package p\n",
+		"a_test.go":        "package p\n",
+		"ignored.go":       "//go:build ignore\n\npackage p\n",
+		"gen.go":           "package p\n",
+		"api/b.go":         "package api\n",
+		"internal/c.go":    "package internal\n",
+		"x_windows_arm.go": "package p\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		ioutil.WriteFile(path, []byte(content), 0644)
+	}
+
+	filter := &FileFilter{Root: dir, Exclude: []string{"gen.go", "internal/..."}}
+	expected := map[string]bool{
+		"a.go":             true,
+		"a_test.go":        false,
+		"ignored.go":       false,
+		"gen.go":           false,
+		"api/b.go":         true,
+		"internal/c.go":    false,
+		"x_windows_arm.go": false,
+	}
+	for name, shouldMatch := range expected {
+		if filter.Match(name) != shouldMatch {
+			t.Errorf("Expected match of %s to be %v.", name, shouldMatch)
+		}
+	}
+
+	filter = &FileFilter{Root: dir, Include: []string{"api/..."}}
+	if filter.Match("a.go") {
+		t.Error("Expected a.go to be excluded when only api/... is included.")
+	}
+}
+
+func TestFileFilterSkipDir(t *testing.T) {
+	filter := &FileFilter{Exclude: []string{"gen"}}
+	expected := map[string]bool{
+		".":          false,
+		"a":          false,
+		"a/testdata": true,
+		"vendor":     true,
+		".git":       true,
+		"_obj":       true,
+		"a/gen":      true,
+	}
+	for dir, shouldSkip := range expected {
+		if filter.SkipDir(dir) != shouldSkip {
+			t.Errorf("Expected skipping %s to be %v.", dir, shouldSkip)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/s2gatev/gocompat/cst"
+)
+
+// Bump is the part of a semantic version that has to be incremented for a
+// set of changes.
+type Bump int
+
+const (
+	// PatchBump is required when the interface has not changed.
+	PatchBump Bump = iota
+
+	// MinorBump is required for compatible changes, e.g. added symbols.
+	MinorBump
+
+	// MajorBump is required for breaking changes.
+	MajorBump
+)
+
+func (b Bump) String() string {
+	switch b {
+	case MajorBump:
+		return "major"
+	case MinorBump:
+		return "minor"
+	default:
+		return "patch"
+	}
+}
+
+func (b Bump) MarshalText() ([]byte, error) {
+	return []byte(b.String()), nil
+}
+
+// recommendBump returns the version bump required by a set of changes -
+// major for breaking changes, minor for compatible ones and patch otherwise.
+func recommendBump(changes []cst.Change) Bump {
+	bump := PatchBump
+	for _, change := range changes {
+		if change.IsBreaking() {
+			return MajorBump
+		}
+		bump = MinorBump
+	}
+	return bump
+}
+
+// Version is a semantic version of the form vMAJOR.MINOR.PATCH.
+type Version struct {
+	Major, Minor, Patch int
+}
+
+var versionPattern = regexp.MustCompile(`^v([0-9]+)\.([0-9]+)\.([0-9]+)$`)
+
+// parseVersion parses a release version. Pre-release versions are not accepted.
+func parseVersion(s string) (Version, bool) {
+	match := versionPattern.FindStringSubmatch(s)
+	if match == nil {
+		return Version{}, false
+	}
+	major, _ := strconv.Atoi(match[1])
+	minor, _ := strconv.Atoi(match[2])
+	patch, _ := strconv.Atoi(match[3])
+	return Version{major, minor, patch}, true
+}
+
+// Less returns if the version precedes another one.
+func (v Version) Less(other Version) bool {
+	if v.Major != other.Major {
+		return v.Major < other.Major
+	}
+	if v.Minor != other.Minor {
+		return v.Minor < other.Minor
+	}
+	return v.Patch < other.Patch
+}
+
+// Next returns the smallest version with the given bump applied. Breaking
+// changes in major version zero only require a minor bump.
+func (v Version) Next(bump Bump) Version {
+	if bump == MajorBump && v.Major == 0 {
+		bump = MinorBump
+	}
+	switch bump {
+	case MajorBump:
+		return Version{v.Major + 1, 0, 0}
+	case MinorBump:
+		return Version{v.Major, v.Minor + 1, 0}
+	default:
+		return Version{v.Major, v.Minor, v.Patch + 1}
+	}
+}
+
+func (v Version) String() string {
+	return fmt.Sprintf("v%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
+
+// latestVersionTag returns the greatest release version tagged in a git repository.
+func latestVersionTag(dir string) (Version, bool, error) {
+	output, err := git(dir, "tag", "--list", "v*")
+	if err != nil {
+		return Version{}, false, err
+	}
+
+	latest, found := Version{}, false
+	for _, tag := range strings.Fields(string(output)) {
+		if version, ok := parseVersion(tag); ok && (!found || latest.Less(version)) {
+			latest, found = version, true
+		}
+	}
+	return latest, found, nil
+}
+
+var majorSuffix = regexp.MustCompile(`[/.]v([0-9]+)$`)
+
+// moduleMajor returns the major version implied by a module path - N for
+// paths ending in /vN (or .vN for gopkg.in) and 1 for paths without suffix.
+func moduleMajor(modulePath string) int {
+	if match := majorSuffix.FindStringSubmatch(modulePath); match != nil {
+		major, _ := strconv.Atoi(match[1])
+		return major
+	}
+	return 1
+}
+
+// verifyRelease checks that releasing a version after the latest one is
+// consistent with the required bump and the module path. It returns a
+// description of every problem found.
+func verifyRelease(latest Version, bump Bump, release *Version, modulePath string) []string {
+	problems := []string{}
+	required := latest.Next(bump)
+
+	if release != nil && release.Less(required) {
+		problems = append(problems, fmt.Sprintf(
+			"version %s does not include the %s bump required after %s, release %s or later",
+			release, bump, latest, required))
+	}
+
+	major := required.Major
+	if release != nil && release.Major > major {
+		major = release.Major
+	}
+	return append(problems, verifyModulePath(major, modulePath)...)
+}
+
+// verifyModulePath checks that the module path matches a major version.
+func verifyModulePath(major int, modulePath string) []string {
+	if major >= 2 && moduleMajor(modulePath) != major {
+		return []string{fmt.Sprintf(
+			"module path %s does not match major version %d, it should end with /v%d",
+			modulePath, major, major)}
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/s2gatev/gocompat/cst"
+)
+
+func TestRecommendBump(t *testing.T) {
+	base := `
+package p
+
+func A() {
+}
+`
+
+	cases := map[string]Bump{
+		base:                       PatchBump,
+		base + "\nfunc B() {\n}\n": MinorBump,
+		"\npackage p\n":            MajorBump,
+	}
+
+	for newer, expected := range cases {
+		if bump := recommendBump(cst.Diff(parse(base), parse(newer))); bump != expected {
+			t.Errorf("Expected %s bump for %q, got %s.", expected, newer, bump)
+		}
+	}
+}
+
+func TestVersionNext(t *testing.T) {
+	cases := []struct {
+		version  Version
+		bump     Bump
+		expected string
+	}{
+		{Version{1, 4, 2}, PatchBump, "v1.4.3"},
+		{Version{1, 4, 2}, MinorBump, "v1.5.0"},
+		{Version{1, 4, 2}, MajorBump, "v2.0.0"},
+		{Version{0, 4, 2}, MajorBump, "v0.5.0"},
+	}
+
+	for _, c := range cases {
+		if next := c.version.Next(c.bump).String(); next != c.expected {
+			t.Errorf("Expected %s after %s with %s bump, got %s.", c.expected, c.version, c.bump, next)
+		}
+	}
+}
+
+func TestVerifyRelease(t *testing.T) {
+	latest := Version{1, 4, 0}
+	patch := Version{1, 4, 1}
+	major := Version{2, 0, 0}
+
+	cases := []struct {
+		bump       Bump
+		release    *Version
+		modulePath string
+		problems   int
+	}{
+		{MinorBump, nil, "example.com/m", 0},
+		{MajorBump, nil, "example.com/m", 1},
+		{MajorBump, nil, "example.com/m/v2", 0},
+		{MajorBump, &patch, "example.com/m/v2", 1},
+		{MinorBump, &patch, "example.com/m", 1},
+		{PatchBump, &major, "example.com/m", 1},
+		{PatchBump, &major, "gopkg.in/m.v2", 0},
+	}
+
+	for _, c := range cases {
+		problems := verifyRelease(latest, c.bump, c.release, c.modulePath)
+		if len(problems) != c.problems {
+			t.Errorf("Expected %d problems for %s bump of %s, got %v.", c.problems, c.bump, c.modulePath, problems)
+		}
+	}
+}
+
+func TestVerifyModulePath(t *testing.T) {
+	if problems := verifyModulePath(2, "example.com/m"); len(problems) != 1 {
+		t.Errorf("Expected a problem for major version 2 without suffix, got %v.", problems)
+	}
+	if problems := verifyModulePath(1, "example.com/m"); len(problems) != 0 {
+		t.Errorf("Expected no problems for major version 1, got %v.", problems)
+	}
+}
@@ -0,0 +1,494 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/token"
+	"go/types"
+	"os"
+	"sort"
+	"strconv"
+
+	"github.com/s2gatev/gocompat/cst"
+)
+
+// addFile records a parsed file to be type checked with its package.
+func (ic *InterfaceContext) addFile(file *ast.File) {
+	if ic.packageFiles == nil {
+		ic.packageFiles = map[string][]*ast.File{}
+	}
+	path := ic.importPath(file)
+	ic.packageFiles[path] = append(ic.packageFiles[path], file)
+}
+
+// checkTypes type checks the recorded packages with go/types and builds
+// their interface from the resulting objects. Packages outside of the
+// project are imported from source, their types are invalid if they can
+// not be found. Type errors are reported on stderr.
+func (ic *InterfaceContext) checkTypes() {
+	checker := &typeChecker{
+		context:  ic,
+		checked:  map[string]*types.Package{},
+		fallback: importer.ForCompiler(ic.FileSet, "source", nil),
+	}
+
+	paths := []string{}
+	for path := range ic.packageFiles {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		checker.Import(path)
+	}
+	ic.handleDependencies()
+}
+
+// handleDependencies records the method sets of the types declared outside
+// of the project and referred to by its interface.
+func (ic *InterfaceContext) handleDependencies() {
+	for obj := range ic.dependencies {
+		path := obj.Pkg().Path()
+		if _, ok := ic.packageFiles[path]; ok {
+			continue
+		}
+		if ic.Project.Dependencies == nil {
+			ic.Project.Dependencies = map[string]*cst.Package{}
+		}
+		pkg, ok := ic.Project.Dependencies[path]
+		if !ok {
+			pkg = &cst.Package{
+				Name:  obj.Pkg().Name(),
+				Nodes: map[string]cst.Node{},
+				Path:  path,
+			}
+			ic.Project.Dependencies[path] = pkg
+		}
+		converter := &typeConverter{pkg: obj.Pkg(), fileSet: ic.FileSet}
+		pkg.Nodes[obj.Name()] = converter.dependency(obj)
+	}
+}
+
+// typeChecker type checks the packages of the project, importing them from
+// the parsed files as they are needed.
+type typeChecker struct {
+	context  *InterfaceContext
+	checked  map[string]*types.Package
+	fallback types.Importer
+}
+
+func (tc *typeChecker) Import(path string) (*types.Package, error) {
+	files, ok := tc.context.packageFiles[path]
+	if !ok {
+		return tc.fallback.Import(path)
+	}
+	if pkg, ok := tc.checked[path]; ok {
+		if pkg == nil {
+			return nil, fmt.Errorf("import cycle through %s", path)
+		}
+		return pkg, nil
+	}
+	tc.checked[path] = nil
+
+	// Type errors are reported, but tolerated. The affected types are
+	// invalid and never compare equal.
+	config := types.Config{
+		Importer: tc,
+		Error: func(err error) {
+			fmt.Fprintln(os.Stderr, "Error when type checking package.", err)
+		},
+		FakeImportC: true,
+	}
+	info := &types.Info{Types: map[ast.Expr]types.TypeAndValue{}}
+	pkg, _ := config.Check(path, tc.context.FileSet, files, info)
+	tc.checked[path] = pkg
+
+	tc.context.handleTypesPackage(pkg, files, info)
+	return pkg, nil
+}
+
+// handleTypesPackage adds the exported objects of a type checked package to
+// the project.
+func (ic *InterfaceContext) handleTypesPackage(pkg *types.Package, files []*ast.File, info *types.Info) {
+	if !ic.IncludeInternal && !isExportedPackage(pkg.Path(), pkg.Name()) {
+		return
+	}
+
+	current := &cst.Package{
+		Name:  pkg.Name(),
+		Nodes: map[string]cst.Node{},
+		Path:  pkg.Path(),
+	}
+	ic.Project.Packages[pkg.Path()] = current
+
+	if ic.dependencies == nil {
+		ic.dependencies = map[*types.TypeName]bool{}
+	}
+	converter := &typeConverter{pkg: pkg, fileSet: ic.FileSet, dependencies: ic.dependencies}
+
+	// Defined types are described by the type they are declared with rather
+	// than their underlying type, as done by the syntactic builder.
+	declared := map[string]types.Type{}
+	sealed := map[string]bool{}
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			genDecl, ok := decl.(*ast.GenDecl)
+			if !ok || genDecl.Tok != token.TYPE {
+				continue
+			}
+			var doc *ast.CommentGroup
+			if !genDecl.Lparen.IsValid() {
+				doc = genDecl.Doc
+			}
+			for _, spec := range genDecl.Specs {
+				typeSpec := spec.(*ast.TypeSpec)
+				declared[typeSpec.Name.Name] = info.TypeOf(typeSpec.Type)
+				sealed[typeSpec.Name.Name] = hasMarker(sealedMarker, typeSpec.Doc, doc)
+			}
+		}
+	}
+
+	scope := pkg.Scope()
+	for _, name := range scope.Names() {
+		switch obj := scope.Lookup(name).(type) {
+		case *types.TypeName:
+			// Unexported types are recorded aside from the interface, as
+			// exported members are promoted from them.
+			nodes := current.Nodes
+			if !obj.Exported() {
+				if current.Unexported == nil {
+					current.Unexported = map[string]cst.Node{}
+				}
+				nodes = current.Unexported
+			}
+			if obj.IsAlias() {
+				nodes[name] = converter.alias(obj, declared[name])
+			} else {
+				nodes[name] = converter.typeDef(obj, declared[name], sealed[name])
+			}
+		case *types.Func:
+			if obj.Exported() {
+				current.Nodes[name] = converter.function(obj)
+			}
+		case *types.Var:
+			if obj.Exported() {
+				current.Nodes[name] = &cst.Var{
+					Name: name,
+					Type: converter.typ(obj.Type()),
+					Pos:  converter.position(obj.Pos()),
+				}
+			}
+		case *types.Const:
+			if obj.Exported() {
+				current.Nodes[name] = &cst.Const{
+					Name:  name,
+					Type:  converter.typ(obj.Type()),
+					Value: constString(obj.Val()),
+					Pos:   converter.position(obj.Pos()),
+				}
+			}
+		}
+	}
+}
+
+// typeConverter converts go/types types of a package to CST types.
+type typeConverter struct {
+	pkg     *types.Package
+	fileSet *token.FileSet
+
+	// dependencies collects the named types of other packages referred to
+	// by the converted types, if set.
+	dependencies map[*types.TypeName]bool
+}
+
+// position returns the file:line position of an object.
+func (tc *typeConverter) position(pos token.Pos) string {
+	p := tc.fileSet.Position(pos)
+	return fmt.Sprintf("%s:%d", p.Filename, p.Line)
+}
+
+func (tc *typeConverter) typeDef(obj *types.TypeName, declared types.Type, sealed bool) *cst.TypeDef {
+	if declared == nil {
+		declared = obj.Type()
+		if named, ok := declared.(*types.Named); ok {
+			declared = named.Underlying()
+		}
+	}
+
+	typeDef := &cst.TypeDef{
+		Name:    obj.Name(),
+		Type:    tc.typ(declared),
+		Pos:     tc.position(obj.Pos()),
+		Methods: map[string]*cst.Func{},
+	}
+	if i, ok := typeDef.Type.(*cst.Interface); ok && sealed {
+		i.Sealed = true
+	}
+
+	if named, ok := obj.Type().(*types.Named); ok {
+		typeDef.TypeParams = tc.typeParams(named.TypeParams())
+		if _, ok := named.Underlying().(*types.Interface); !ok {
+			for i := 0; i < named.NumMethods(); i++ {
+				if method := named.Method(i); method.Exported() {
+					typeDef.Methods[method.Name()] = tc.function(method)
+				}
+			}
+		}
+	}
+	return typeDef
+}
+
+// dependency converts a type declared outside of the project to a type
+// definition holding only its method set and basic underlying type. Interfaces are flattened, so that
+// their method sets are known without their embedded interfaces.
+func (tc *typeConverter) dependency(obj *types.TypeName) *cst.TypeDef {
+	typeDef := &cst.TypeDef{Name: obj.Name(), Methods: map[string]*cst.Func{}}
+	t := types.Unalias(obj.Type())
+
+	if i, ok := t.Underlying().(*types.Interface); ok {
+		iface := &cst.Interface{Funcs: map[string]*cst.Func{}}
+		for n := 0; n < i.NumMethods(); n++ {
+			method := i.Method(n)
+			if !method.Exported() {
+				iface.Sealed = true
+				continue
+			}
+			params, results := tc.signature(method.Type().(*types.Signature))
+			iface.Funcs[method.Name()] = &cst.Func{Name: method.Name(), Params: params, Results: results}
+		}
+		typeDef.Type = iface
+		return typeDef
+	}
+
+	// Basic underlying types are kept, as untyped constants convert to them.
+	if basic, ok := t.Underlying().(*types.Basic); ok {
+		typeDef.Type = &cst.SimpleType{basic.Name()}
+	}
+
+	values := types.NewMethodSet(t)
+	pointers := types.NewMethodSet(types.NewPointer(t))
+	for n := 0; n < pointers.Len(); n++ {
+		method := pointers.At(n).Obj().(*types.Func)
+		if !method.Exported() {
+			continue
+		}
+		reciever := obj.Name()
+		if values.Lookup(method.Pkg(), method.Name()) == nil {
+			reciever = "*" + reciever
+		}
+		params, results := tc.signature(method.Type().(*types.Signature))
+		typeDef.Methods[method.Name()] = &cst.Func{
+			Name:      method.Name(),
+			Recievers: &cst.Recievers{[]cst.Type{&cst.SimpleType{reciever}}},
+			Params:    params,
+			Results:   results,
+		}
+	}
+	return typeDef
+}
+
+func (tc *typeConverter) alias(obj *types.TypeName, declared types.Type) *cst.Alias {
+	a := &cst.Alias{Name: obj.Name(), Pos: tc.position(obj.Pos())}
+
+	// Aliases are only materialized when enabled with GODEBUG=gotypesalias=1.
+	if alias, ok := obj.Type().(*types.Alias); ok {
+		a.TypeParams = tc.typeParams(alias.TypeParams())
+		if declared == nil {
+			declared = alias.Rhs()
+		}
+	}
+	if declared == nil {
+		declared = obj.Type()
+	}
+	a.Type = tc.typ(declared)
+	return a
+}
+
+func (tc *typeConverter) function(obj *types.Func) *cst.Func {
+	signature := obj.Type().(*types.Signature)
+	params, results := tc.signature(signature)
+	f := &cst.Func{
+		Name:       obj.Name(),
+		TypeParams: tc.typeParams(signature.TypeParams()),
+		Params:     params,
+		Results:    results,
+		Pos:        tc.position(obj.Pos()),
+	}
+	if recv := signature.Recv(); recv != nil {
+		f.Recievers = &cst.Recievers{[]cst.Type{tc.typ(recv.Type())}}
+	}
+	return f
+}
+
+func (tc *typeConverter) signature(signature *types.Signature) (*cst.Params, *cst.Results) {
+	var params *cst.Params
+	var results *cst.Results
+
+	if n := signature.Params().Len(); n > 0 {
+		params = &cst.Params{tc.tuple(signature.Params())}
+		if signature.Variadic() {
+			last := signature.Params().At(n - 1).Type().(*types.Slice)
+			elem := tc.typ(last.Elem())
+			if st, ok := elem.(*cst.SimpleType); ok {
+				params.Types[n-1] = &cst.SimpleType{"..." + st.Name}
+			} else {
+				params.Types[n-1] = &cst.VariadicType{Elem: elem}
+			}
+		}
+	}
+
+	if signature.Results().Len() > 0 {
+		results = &cst.Results{tc.tuple(signature.Results())}
+	}
+
+	return params, results
+}
+
+func (tc *typeConverter) tuple(tuple *types.Tuple) []cst.Type {
+	tupleTypes := make([]cst.Type, tuple.Len())
+	for i := range tupleTypes {
+		tupleTypes[i] = tc.typ(tuple.At(i).Type())
+	}
+	return tupleTypes
+}
+
+func (tc *typeConverter) typeParams(list *types.TypeParamList) *cst.TypeParams {
+	if list.Len() == 0 {
+		return nil
+	}
+	typeParams := &cst.TypeParams{}
+	for i := 0; i < list.Len(); i++ {
+		param := list.At(i)
+		constraint := param.Constraint()
+
+		// Constraints written as ~int | ~string are wrapped in implicit interfaces.
+		if i, ok := constraint.(*types.Interface); ok && i.IsImplicit() && i.NumEmbeddeds() == 1 {
+			constraint = i.EmbeddedType(0)
+		}
+		typeParams.Params = append(typeParams.Params, &cst.TypeParam{
+			Name:       param.Obj().Name(),
+			Constraint: tc.typ(constraint),
+		})
+	}
+	return typeParams
+}
+
+// typ converts a type to the CST type the syntactic builder produces for
+// its declaration.
+func (tc *typeConverter) typ(t types.Type) cst.Type {
+	switch t := t.(type) {
+	case *types.Basic:
+		if t.Kind() == types.Invalid {
+			return &cst.SimpleType{cst.InvalidType}
+		}
+		return &cst.SimpleType{t.Name()}
+	case *types.Alias:
+		// Aliases are kept by name, they are resolved when comparing.
+		return tc.named(t.Obj(), t.TypeArgs())
+	case *types.Named:
+		return tc.named(t.Obj(), t.TypeArgs())
+	case *types.TypeParam:
+		return &cst.SimpleType{t.Obj().Name()}
+	case *types.Pointer:
+		elem := tc.typ(t.Elem())
+		if st, ok := elem.(*cst.SimpleType); ok {
+			return &cst.SimpleType{"*" + st.Name}
+		}
+		return &cst.PointerType{Elem: elem}
+	case *types.Slice:
+		return &cst.SliceType{Elem: tc.typ(t.Elem())}
+	case *types.Array:
+		return &cst.ArrayType{Len: strconv.FormatInt(t.Len(), 10), Elem: tc.typ(t.Elem())}
+	case *types.Map:
+		return &cst.MapType{Key: tc.typ(t.Key()), Value: tc.typ(t.Elem())}
+	case *types.Chan:
+		dir := cst.BothDir
+		switch t.Dir() {
+		case types.SendOnly:
+			dir = cst.SendDir
+		case types.RecvOnly:
+			dir = cst.RecvDir
+		}
+		return &cst.ChanType{Dir: dir, Elem: tc.typ(t.Elem())}
+	case *types.Signature:
+		params, results := tc.signature(t)
+		return &cst.FuncType{Params: params, Results: results}
+	case *types.Struct:
+		fields := map[string]*cst.Field{}
+		for i := 0; i < t.NumFields(); i++ {
+			field := t.Field(i)
+			fields[field.Name()] = &cst.Field{
+				Name:     field.Name(),
+				Type:     tc.typ(field.Type()),
+				Pos:      tc.position(field.Pos()),
+				Embedded: field.Embedded(),
+				Tag:      t.Tag(i),
+			}
+		}
+		return &cst.Struct{Fields: fields}
+	case *types.Interface:
+		return tc.iface(t)
+	case *types.Union:
+		union := &cst.Union{}
+		for i := 0; i < t.Len(); i++ {
+			term := t.Term(i)
+			union.Terms = append(union.Terms, &cst.Term{Tilde: term.Tilde(), Type: tc.typ(term.Type())})
+		}
+		return union
+	default:
+		return &cst.SimpleType{t.String()}
+	}
+}
+
+// named converts a reference to a named type or alias.
+func (tc *typeConverter) named(obj *types.TypeName, args *types.TypeList) cst.Type {
+	var named cst.Type
+	if obj.Pkg() == nil || obj.Pkg() == tc.pkg {
+		named = &cst.SimpleType{obj.Name()}
+	} else {
+		named = &cst.QualifiedType{Path: obj.Pkg().Path(), Name: obj.Name()}
+		if tc.dependencies != nil && args.Len() == 0 {
+			tc.dependencies[obj] = true
+		}
+	}
+	if args.Len() > 0 {
+		instance := &cst.Instance{Type: named}
+		for i := 0; i < args.Len(); i++ {
+			instance.Args = append(instance.Args, tc.typ(args.At(i)))
+		}
+		return instance
+	}
+	return named
+}
+
+// iface converts an interface type. Interfaces with unexported methods are
+// sealed, as they can not be implemented by other packages.
+func (tc *typeConverter) iface(t *types.Interface) *cst.Interface {
+	i := &cst.Interface{Funcs: map[string]*cst.Func{}}
+	for n := 0; n < t.NumExplicitMethods(); n++ {
+		method := t.ExplicitMethod(n)
+		if !method.Exported() {
+			i.Sealed = true
+			continue
+		}
+		params, results := tc.signature(method.Type().(*types.Signature))
+		i.Funcs[method.Name()] = &cst.Func{
+			Name:    method.Name(),
+			Params:  params,
+			Results: results,
+			Pos:     tc.position(method.Pos()),
+		}
+	}
+	for n := 0; n < t.NumEmbeddeds(); n++ {
+		embedded := tc.typ(t.EmbeddedType(n))
+		if union, ok := embedded.(*cst.Union); ok {
+			i.TypeSet = union
+		} else if st, ok := embedded.(*cst.SimpleType); ok && st.Name == "any" {
+			continue
+		} else {
+			i.Embeds = append(i.Embeds, embedded)
+		}
+	}
+	return i
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/s2gatev/gocompat/cst"
+)
+
+// scanTypes builds the interface of a project from its type checked sources.
+func scanTypes(t *testing.T, files map[string]string) *cst.Project {
+	dir, _ := ioutil.TempDir("", "gocompat")
+	defer os.RemoveAll(dir)
+
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		ioutil.WriteFile(path, []byte(content), 0644)
+	}
+
+	context := &InterfaceContext{
+		Project:    &cst.Project{Packages: map[string]*cst.Package{}},
+		ModulePath: "p",
+		TypeCheck:  true,
+	}
+	if err := scanDir(dir, &FileFilter{Root: dir}, context); err != nil {
+		t.Fatal(err)
+	}
+	return context.Project
+}
+
+func TestTypesBuilderMatchesSyntax(t *testing.T) {
+	source := indexSource + `
+type H[T ~int | ~string] struct {
+	I	T
+	*A
+}
+
+func J[K comparable, V any](m map[K]V) []K {
+	return nil
+}
+
+const (
+	L = iota + 1
+	M
+)
+
+//gocompat:sealed
+type N interface {
+	E
+	O() error
+}
+
+type P = A
+
+type R = map[string]P
+`
+
+	changes := cst.Diff(parse(source), scanTypes(t, map[string]string{"source.go": source}))
+	if len(changes) != 0 {
+		t.Errorf("Expected no changes between the builders, got %v.", changes)
+	}
+}
+
+func TestTypesBuilderInfersTypes(t *testing.T) {
+	project := scanTypes(t, map[string]string{
+		"a.go": `
+package p
+
+import "p/q"
+
+func f() (*q.B, error) {
+	return nil, nil
+}
+
+var A, _ = f()
+
+var C = q.D + 1
+
+const E = 'e'
+`,
+		"q/q.go": `
+package q
+
+type B struct{}
+
+const D int64 = 1
+`,
+	})
+
+	expected := &cst.Package{Name: "p", Path: "p", Nodes: map[string]cst.Node{
+		"A": &cst.Var{Name: "A", Type: &cst.PointerType{Elem: &cst.QualifiedType{Path: "p/q", Name: "B"}}},
+		"C": &cst.Var{Name: "C", Type: &cst.SimpleType{"int64"}},
+		"E": &cst.Const{Name: "E", Type: &cst.SimpleType{"untyped rune"}, Value: "101"},
+	}}
+	for _, change := range cst.Diff(&cst.Project{Packages: map[string]*cst.Package{"p": expected}}, project) {
+		if change.Package == "p" {
+			t.Errorf("Unexpected change %s.", change)
+		}
+	}
+}
+
+func TestTypesBuilderRelaxesDependencyParams(t *testing.T) {
+	older := scanTypes(t, map[string]string{"a.go": `
+package p
+
+import (
+	"io"
+	"os"
+	"time"
+)
+
+func Open(f *os.File) {
+}
+
+func Wait(d time.Duration) {
+}
+
+func Wrap(r io.ReadCloser) {
+}
+
+func Write(r io.Reader) {
+}
+`})
+
+	newer := scanTypes(t, map[string]string{"a.go": `
+package p
+
+import (
+	"fmt"
+	"io"
+)
+
+func Open(r io.Reader) {
+}
+
+func Wait(s fmt.Stringer) {
+}
+
+func Wrap(r io.Reader) {
+}
+
+func Write(w io.Writer) {
+}
+`})
+
+	changes := cst.Diff(older, newer)
+	expected := []string{
+		"p.Open.params.0: changed from *os.File to io.Reader (potentially breaking, *os.File implements io.Reader)",
+		"p.Wait.params.0: changed from time.Duration to fmt.Stringer (breaking, time.Duration implements fmt.Stringer, but untyped constants can not be passed anymore)",
+		"p.Wrap.params.0: changed from io.ReadCloser to io.Reader (potentially breaking, io.ReadCloser implements io.Reader)",
+		"p.Write.params.0: changed from io.Reader to io.Writer",
+	}
+	if len(changes) != len(expected) {
+		t.Fatalf("Expected changes %q, got %v.", expected, changes)
+	}
+	for i, change := range changes {
+		change.Pos = ""
+		if change.String() != expected[i] {
+			t.Errorf("Expected change %q, got %q.", expected[i], change.String())
+		}
+	}
+}
+
+func TestTypesBuilderInvalidTypes(t *testing.T) {
+	older := scanTypes(t, map[string]string{"a.go": `
+package p
+
+import "missing/x"
+
+func F(a x.T) {
+}
+`})
+
+	newer := scanTypes(t, map[string]string{"a.go": `
+package p
+
+import "missing/x"
+
+func F(a x.U) {
+}
+`})
+
+	changes := cst.BreakingChanges(cst.Diff(older, newer))
+	if len(changes) != 1 || changes[0].Path != "p.F.params.0" {
+		t.Errorf("Expected the parameter of invalid type to be reported, got %v.", changes)
+	}
+}
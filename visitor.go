@@ -20,9 +20,13 @@ func (cpv *ContextPassingVisitor) Handle(handler func(ast.Node, interface{})) {
 }
 
 // Visit traverses the ast applying visitor's handlers to each node in the order they are defined.
+// Blocks of statements, e.g. function bodies, only contain local declarations and are not traversed.
 func (cpv *ContextPassingVisitor) Visit(node ast.Node) ast.Visitor {
 	for _, handler := range cpv.Handlers {
 		handler(node, cpv.Context)
 	}
+	if _, ok := node.(*ast.BlockStmt); ok {
+		return nil
+	}
 	return cpv
 }